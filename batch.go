@@ -0,0 +1,382 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rateGroup is the delay between commands sent to Groups, the Bridge recommends
+// sending no more than one Group command per second.
+const rateGroup = time.Second
+
+// Batch is a collection of LightStates and the Lights and Controls they should be
+// applied to. A Batch will attempt to send the least amount of commands to the
+// Bridge by combining targets that share an identical LightState into a single
+// Group command when a Group with the exact same members exists.
+//
+// Targets that cannot be combined are sent one at a time, unless the 'Scratch'
+// value is set.
+type Batch struct {
+	bridge *Bridge
+	e      []batchEntry
+
+	// Scratch is the name of a LightGroup that will be created (or reused if it
+	// already exists) and have it's members changed to match any set of targets
+	// that share a LightState but do not match an existing Group.
+	//
+	// If empty, no scratch LightGroup is used.
+	Scratch string
+}
+
+// BatchResult is the result of applying a Batch to a single Light or Control.
+type BatchResult struct {
+	Err error
+	// Group is the ID of the Group that was used to apply the LightState. This
+	// is empty if the LightState was sent directly to the Light or Control.
+	Group string
+	// ID is the ID of the Light or Control.
+	ID string
+}
+type batchEntry struct {
+	s LightState
+	c *Control
+	l *Light
+}
+type batchSet struct {
+	k string
+	s LightState
+	l []*Light
+	c []*Control
+}
+
+// Batch returns a new empty Batch that can be used to apply LightStates to
+// multiple Lights, Controls and Groups.
+func (b *Bridge) Batch() *Batch {
+	return &Batch{bridge: b}
+}
+
+// Light will add the Lights specified to be set to the supplied LightState.
+//
+// If a Light is added more than once, the last LightState added will be used.
+func (b *Batch) Light(s LightState, l ...*Light) {
+	for i := range l {
+		b.e = append(b.e, batchEntry{s: s, l: l[i], c: &l[i].Control})
+	}
+}
+
+// Control will add the Controls specified to be set to the supplied LightState.
+//
+// If a Control is added more than once, the last LightState added will be used.
+func (b *Batch) Control(s LightState, c ...*Control) {
+	for i := range c {
+		b.e = append(b.e, batchEntry{s: s, c: c[i]})
+	}
+}
+
+// Group will add all the Lights and Controls in the specified Groups to be set
+// to the supplied LightState.
+//
+// If a Light or Control is added more than once, the last LightState added will
+// be used.
+func (b *Batch) Group(s LightState, g ...*Group) {
+	for i := range g {
+		b.Light(s, g[i].Lights...)
+		b.Control(s, g[i].Controls...)
+	}
+}
+func (s *batchSet) ids() []string {
+	r := make([]string, 0, len(s.l)+len(s.c))
+	for i := range s.l {
+		r = append(r, s.l[i].ID)
+	}
+	for i := range s.c {
+		r = append(r, s.c[i].ID)
+	}
+	sort.Strings(r)
+	return r
+}
+func members(g *Group) []string {
+	r := make([]string, 0, len(g.Lights)+len(g.Controls))
+	for i := range g.Lights {
+		r = append(r, g.Lights[i].ID)
+	}
+	for i := range g.Controls {
+		r = append(r, g.Controls[i].ID)
+	}
+	sort.Strings(r)
+	return r
+}
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply will send all the LightStates in the Batch to their targets using the
+// Base context of the Bridge.
+//
+// See 'ApplyContext' for more info.
+func (b *Batch) Apply() ([]BatchResult, error) {
+	return b.ApplyContext(b.bridge.ctx)
+}
+func (b *Bridge) findGroup(n []string) *Group {
+	if b.all != nil && equal(members(b.all), n) {
+		return b.all
+	}
+	for _, g := range b.groups {
+		if equal(members(g), n) {
+			return g
+		}
+	}
+	return nil
+}
+func (b *Batch) sets() []*batchSet {
+	var (
+		o = make([]*batchSet, 0)
+		t = make(map[*Control]int, len(b.e))
+	)
+	for i := range b.e {
+		t[b.e[i].c] = i
+	}
+	m := make(map[string]*batchSet)
+	for i := range b.e {
+		if t[b.e[i].c] != i {
+			continue
+		}
+		// Only the values supported by each target are compared, so targets
+		// are only combined when every value sent is supported by all of them.
+		s := b.e[i].s
+		if b.e[i].l != nil {
+			s.mask = b.e[i].l.supported(s.mask)
+		} else {
+			s.mask &= maskOn | maskAlert
+		}
+		d, _ := s.controlState.marshal(s.mask)
+		v, ok := m[string(d)]
+		if !ok {
+			v = &batchSet{k: string(d), s: s}
+			m[v.k] = v
+			o = append(o, v)
+		}
+		if b.e[i].l != nil {
+			v.l = append(v.l, b.e[i].l)
+		} else {
+			v.c = append(v.c, b.e[i].c)
+		}
+	}
+	return o
+}
+func (b *Bridge) scratch(x context.Context, n string, g *Group, s *batchSet) (*Group, error) {
+	i := s.ids()
+	if g == nil {
+		v, err := b.createGroup(x, n, LightGroup, i)
+		if err != nil {
+			return nil, err
+		}
+		g = &Group{ID: v, name: n, bridge: b, Type: LightGroup}
+		b.lock.Lock()
+		if b.groups != nil {
+			b.groups[g.ID] = g
+		}
+		b.lock.Unlock()
+	} else {
+		d, err := json.Marshal(map[string]interface{}{"lights": i})
+		if err != nil {
+			return nil, err
+		}
+		if _, err = b.request(x, http.MethodPut, "/groups/"+g.ID, d); err != nil {
+			return nil, err
+		}
+	}
+	b.lock.Lock()
+	g.Lights, g.Controls = s.l, s.c
+	b.lock.Unlock()
+	return g, nil
+}
+func (b *Bridge) loadBatch(x context.Context) error {
+	// Any caches that are missing are fetched before taking the lock, so lookups
+	// are not blocked while waiting on the Bridge.
+	b.lock.RLock()
+	var (
+		r [4][]byte
+		g = b.groups == nil || b.all == nil
+		p = [4]bool{g && (b.lights == nil || b.controls == nil), g && b.sensors == nil, b.groups == nil, b.all == nil}
+	)
+	b.lock.RUnlock()
+	for i, u := range [4]string{"/lights", "/sensors", "/groups", "/groups/0"} {
+		if !p[i] {
+			continue
+		}
+		var err error
+		if r[i], err = b.request(x, http.MethodGet, u, nil); err != nil {
+			return err
+		}
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if len(r[0]) > 0 && (b.lights == nil || b.controls == nil) {
+		if err := b.loadControls(r[0]); err != nil {
+			return err
+		}
+	}
+	if len(r[1]) > 0 && b.sensors == nil {
+		if err := b.loadSensors(r[1]); err != nil {
+			return err
+		}
+	}
+	if len(r[2]) > 0 && b.groups == nil {
+		if err := b.loadGroups(r[2]); err != nil {
+			return err
+		}
+	}
+	if p[3] && b.all == nil {
+		return b.loadGroupAll(r[3])
+	}
+	return nil
+}
+func (b *Bridge) scratchGroup(n string) *Group {
+	for _, v := range b.groups {
+		if v.Type == LightGroup && strings.EqualFold(v.name, n) {
+			return v
+		}
+	}
+	return nil
+}
+
+// ApplyContext will send all the LightStates in the Batch to their targets.
+//
+// Targets that share the same LightState are combined into a single Group
+// command if there is a Group with the exact same members. Values that a target
+// does not support are removed before comparing, so the combined command only
+// contains values that are supported by every member. Otherwise, if the
+// 'Scratch' value is set, the scratch LightGroup will be changed to contain
+// the targets and used instead. Any remaining targets, or targets where the
+// scratch LightGroup could not be changed, are sent the LightState directly.
+//
+// Commands are sent at a rate that respects the Bridge rate limits. This
+// function returns a BatchResult for each Light and Control and will only
+// return an error if the Groups could not be loaded from the Bridge.
+//
+// This function allows a Context to be specified to be used instead of the
+// Bridge's base Context.
+func (b *Batch) ApplyContext(x context.Context) ([]BatchResult, error) {
+	if err := b.bridge.loadBatch(x); err != nil {
+		return nil, err
+	}
+	b.bridge.lock.Lock()
+	var (
+		s = b.sets()
+		t = make([]*Group, len(s))
+		c *Group
+	)
+	// Only the Group membership is read under the lock, the commands are sent
+	// once it is released so the Bridge can be used while waiting.
+	for i, v := range s {
+		if len(v.l)+len(v.c) > 1 {
+			t[i] = b.bridge.findGroup(v.ids())
+		}
+	}
+	if len(b.Scratch) > 0 {
+		c = b.bridge.scratchGroup(b.Scratch)
+	}
+	b.bridge.lock.Unlock()
+	var (
+		o    = make([]BatchResult, 0, len(b.e))
+		l, g time.Time
+	)
+	for i, v := range s {
+		var (
+			q   = t[i]
+			err error
+		)
+		if q == nil && len(b.Scratch) > 0 && len(v.l)+len(v.c) > 1 {
+			if err = wait(x, g, rateGroup); err == nil {
+				q, err = b.bridge.scratch(x, b.Scratch, c, v)
+			}
+			switch g = time.Now(); {
+			case err == nil:
+				c = q
+			case x.Err() == nil:
+				// The scratch LightGroup could not be changed, so fall back to
+				// sending the LightState to each target.
+				err = nil
+			}
+		}
+		if q != nil {
+			if err = wait(x, g, rateGroup); err == nil {
+				err = q.Apply(x, v.s)
+			}
+			g = time.Now()
+		}
+		if q != nil || err != nil {
+			var n string
+			if q != nil {
+				n = q.ID
+			}
+			for _, e := range v.l {
+				o = append(o, BatchResult{ID: e.ID, Group: n, Err: err})
+			}
+			for _, e := range v.c {
+				o = append(o, BatchResult{ID: e.ID, Group: n, Err: err})
+			}
+			continue
+		}
+		for _, e := range v.l {
+			if err = wait(x, l, rateLight); err == nil {
+				err = e.Apply(x, v.s)
+			}
+			l = time.Now()
+			o = append(o, BatchResult{ID: e.ID, Err: err})
+		}
+		for _, e := range v.c {
+			if err = wait(x, l, rateLight); err == nil {
+				err = e.Apply(x, v.s)
+			}
+			l = time.Now()
+			o = append(o, BatchResult{ID: e.ID, Err: err})
+		}
+	}
+	return o, nil
+}
+func wait(x context.Context, t time.Time, r time.Duration) error {
+	if t.IsZero() {
+		return nil
+	}
+	d := time.Until(t.Add(r))
+	if d <= 0 {
+		return nil
+	}
+	v := time.NewTimer(d)
+	select {
+	case <-x.Done():
+		v.Stop()
+		return x.Err()
+	case <-v.C:
+	}
+	return nil
+}
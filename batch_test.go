@@ -0,0 +1,178 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBatchRoutes() map[string]string {
+	return map[string]string{
+		"GET /lights":       testLights("1", "2", "3"),
+		"GET /sensors":      testSensors,
+		"GET /groups":       `{"1":{"name":"Room","type":"Room","lights":["1","2"],"action":{"on":false,"alert":"none"}}}`,
+		"GET /groups/0":     `{"name":"Group 0","type":"LightGroup","lights":["1","2","3"],"action":{"on":false,"alert":"none"}}`,
+		"GET /capabilities": `{"groups":{"available":10,"total":64}}`,
+		"GET /config":       `{"name":"Bridge","apiversion":"1.50.0"}`,
+	}
+}
+func checkBatch(t *testing.T, r []BatchResult, g map[string]string) {
+	if len(r) != len(g) {
+		t.Fatalf("Apply returned %d results, want %d", len(r), len(g))
+	}
+	for _, v := range r {
+		if v.Err != nil {
+			t.Errorf("Apply result %q: unexpected error: %s", v.ID, v.Err)
+		}
+		if n, ok := g[v.ID]; !ok || n != v.Group {
+			t.Errorf("Apply result %q used Group %q, want %q", v.ID, v.Group, n)
+		}
+	}
+}
+func checkRequests(t *testing.T, s *testServer, p ...string) {
+	r := s.requests()
+	if len(r) != len(p) {
+		t.Fatalf("server received requests %q, want %d requests", r, len(p))
+	}
+	for i := range p {
+		if !strings.HasPrefix(r[i], p[i]) {
+			t.Errorf("request %d is %q, want prefix %q", i, r[i], p[i])
+		}
+	}
+}
+func TestBatchGroup(t *testing.T) {
+	b, s := newTestBridge(t, testBatchRoutes())
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	var o, d LightState
+	o.SetOn(true)
+	d.SetBrightness(128)
+	v := b.Batch()
+	v.Light(o, l["1"], l["2"])
+	v.Light(d, l["3"])
+	r, err := v.ApplyContext(context.Background())
+	if err != nil {
+		t.Fatalf("ApplyContext failed: %s", err)
+	}
+	checkBatch(t, r, map[string]string{"1": "1", "2": "1", "3": ""})
+	checkRequests(t, s, `PUT /groups/1/action {"on":true}`, `PUT /lights/3/state {"bri":128}`)
+}
+func TestBatchScratch(t *testing.T) {
+	b, s := newTestBridge(t, testBatchRoutes())
+	s.set("POST /groups", `[{"success":{"id":"7"}}]`)
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	var o LightState
+	o.SetOn(true)
+	v := b.Batch()
+	v.Scratch = "Batch"
+	v.Light(o, l["1"], l["3"])
+	r, err := v.ApplyContext(context.Background())
+	if err != nil {
+		t.Fatalf("ApplyContext failed: %s", err)
+	}
+	checkBatch(t, r, map[string]string{"1": "7", "3": "7"})
+	checkRequests(t, s, `POST /groups {"lights":["1","3"],"name":"Batch","type":"LightGroup"}`, `PUT /groups/7/action {"on":true}`)
+}
+func TestBatchScratchFallback(t *testing.T) {
+	b, s := newTestBridge(t, testBatchRoutes())
+	s.set("POST /groups", `[{"error":{"type":301,"address":"/groups","description":"groups table full"}}]`)
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	var o LightState
+	o.SetOn(true)
+	v := b.Batch()
+	v.Scratch = "Batch"
+	v.Light(o, l["1"], l["3"])
+	r, err := v.ApplyContext(context.Background())
+	if err != nil {
+		t.Fatalf("ApplyContext failed: %s", err)
+	}
+	checkBatch(t, r, map[string]string{"1": "", "3": ""})
+	checkRequests(t, s, "POST /groups ", `PUT /lights/1/state {"on":true}`, `PUT /lights/3/state {"on":true}`)
+}
+func TestBatchUnlocked(t *testing.T) {
+	b, s := newTestBridge(t, testBatchRoutes())
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	var (
+		o LightState
+		h = s.hold("GET /groups")
+		e = make(chan error, 1)
+		v = b.Batch()
+	)
+	o.SetOn(true)
+	v.Light(o, l["1"], l["2"])
+	go func() {
+		_, err := v.ApplyContext(context.Background())
+		e <- err
+	}()
+	<-h
+	d := make(chan struct{})
+	go func() {
+		b.Light("1")
+		close(d)
+	}()
+	select {
+	case <-d:
+	case <-time.After(time.Second * 5):
+		h <- struct{}{}
+		t.Fatalf("Light lookup was blocked while the Batch loaded the Groups")
+	}
+	h <- struct{}{}
+	if err = <-e; err != nil {
+		t.Fatalf("ApplyContext failed: %s", err)
+	}
+	checkRequests(t, s, `PUT /groups/1/action {"on":true}`)
+}
+func TestBatchMixedKinds(t *testing.T) {
+	r := testBatchRoutes()
+	r["GET /lights"] = strings.Replace(testLights("1", "2"), "}}}}", `}}}},"3":{"name":"Plug","uniqueid":"00:17:88:01:00:00:00:03-0b","type":"On/Off plug-in unit","modelid":"LOM001","productname":"Hue Smart plug","state":{"on":false,"alert":"none","reachable":true}}`, 1)
+	r["GET /groups"] = `{"1":{"name":"Room","type":"Room","lights":["1","2","3"],"action":{"on":false,"alert":"none"}},"2":{"name":"Lamps","type":"Zone","lights":["1","2"],"action":{"on":false,"alert":"none"}}}`
+	b, s := newTestBridge(t, r)
+	g := b.Group("1")
+	if g == nil || len(g.Lights) != 2 || len(g.Controls) != 1 {
+		t.Fatalf("Group did not return the Group with two Lights and a Control")
+	}
+	var o, d LightState
+	o.SetOn(true)
+	d.SetOn(true)
+	d.SetBrightness(128)
+	v := b.Batch()
+	v.Group(o, g)
+	if _, err := v.ApplyContext(context.Background()); err != nil {
+		t.Fatalf("ApplyContext failed: %s", err)
+	}
+	v = b.Batch()
+	v.Group(d, g)
+	x, err := v.ApplyContext(context.Background())
+	if err != nil {
+		t.Fatalf("ApplyContext failed: %s", err)
+	}
+	checkBatch(t, x, map[string]string{"1": "2", "2": "2", "3": ""})
+	checkRequests(t, s, `PUT /groups/0/action {"on":true}`, `PUT /groups/2/action {"bri":128,"on":true}`, `PUT /lights/3/state {"on":true}`)
+}
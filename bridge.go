@@ -30,22 +30,34 @@ import (
 
 const timeoutDefault = time.Second * 10
 
+// rateLight is the delay between commands sent to individual Lights, the Bridge
+// recommends sending no more than ten Light commands per second.
+const rateLight = time.Second / 10
+
 // Bridge represents a Hue Bridge and can be used to connect and control all
 // the connected devices.
 type Bridge struct {
 	lock sync.RWMutex
+	// auth guards the 'addr' and 'key' values. This is separate from 'lock' as
+	// requests are made while 'lock' is held.
+	auth sync.RWMutex
 
 	ctx    context.Context
 	groups map[string]*Group
 
 	all      *Group
+	config   *BridgeConfig
 	client   *http.Client
 	lights   map[string]*Light
 	sensors  map[string]*Sensor
 	controls map[string]*Control
 
-	addr    string
-	Timeout time.Duration
+	addr, base string
+	key        string
+	Timeout    time.Duration
+	// install is true if 'InstallUpdate' was called and 'WaitForUpdate' has
+	// not seen the install complete.
+	install bool
 }
 type errval struct {
 	e error
@@ -262,7 +274,8 @@ func (b *Bridge) SensorByName(n string) *Sensor {
 }
 func (r *response) UnmarshalJSON(d []byte) error {
 	if d[0] == '{' {
-		*r = d
+		// The decoder may reuse 'd' after returning, so it must be copied.
+		*r = append(response(nil), d...)
 		return nil
 	}
 	var (
@@ -275,7 +288,10 @@ func (r *response) UnmarshalJSON(d []byte) error {
 		return &errval{s: `could not unmarshal JSON response`, e: err}
 	}
 	for i := range m {
-		if _, ok = m[i]["success"]; ok {
+		if v, ok = m[i]["success"]; ok {
+			if len(*r) == 0 && len(v) > 0 && v[0] == '{' {
+				*r = response(v)
+			}
 			continue
 		}
 		if v, ok = m[i]["error"]; !ok {
@@ -341,14 +357,17 @@ func (b *Bridge) getGroups(x context.Context) error {
 	if err != nil || len(r) == 0 {
 		return err
 	}
+	return b.loadGroups(r)
+}
+func (b *Bridge) loadGroups(r []byte) error {
 	m := make(map[string]json.RawMessage)
-	if err = json.Unmarshal(r, &m); err != nil || len(m) == 0 {
+	if err := json.Unmarshal(r, &m); err != nil || len(m) == 0 {
 		return &errval{s: "could not unmarshal Group JSON", e: err}
 	}
 	b.groups = make(map[string]*Group, len(m))
 	for k, v := range m {
 		g := new(Group)
-		if err = g.unmarshal(k, b, v); err != nil {
+		if err := g.unmarshal(k, b, v); err != nil {
 			return &errval{s: `could not unmarshal Group "` + k + `" JSON`, e: err}
 		}
 		b.groups[k] = g
@@ -360,14 +379,17 @@ func (b *Bridge) getSensors(x context.Context) error {
 	if err != nil || len(r) == 0 {
 		return err
 	}
+	return b.loadSensors(r)
+}
+func (b *Bridge) loadSensors(r []byte) error {
 	m := make(map[string]json.RawMessage)
-	if err = json.Unmarshal(r, &m); err != nil || len(m) == 0 {
+	if err := json.Unmarshal(r, &m); err != nil || len(m) == 0 {
 		return &errval{s: "could not unmarshal Sensor JSON", e: err}
 	}
 	b.sensors = make(map[string]*Sensor, len(m))
 	for k, v := range m {
 		s := new(Sensor)
-		if err = s.unmarshal(k, b, v); err != nil {
+		if err := s.unmarshal(k, b, v); err != nil {
 			return &errval{s: `could not unmarshal Sensor "` + k + `" JSON`, e: err}
 		}
 		b.sensors[k] = s
@@ -402,11 +424,17 @@ func (b *Bridge) getGroupAll(x context.Context) error {
 		}
 	}
 	r, err := b.request(x, http.MethodGet, "/groups/0", nil)
-	if err != nil || len(r) == 0 {
+	if err != nil {
 		return &errval{s: "could not unmarshal All Group JSON", e: err}
 	}
+	return b.loadGroupAll(r)
+}
+func (b *Bridge) loadGroupAll(r []byte) error {
+	if len(r) == 0 {
+		return &errval{s: "could not unmarshal All Group JSON"}
+	}
 	b.all = new(Group)
-	if err = b.all.unmarshal("0", b, r); err != nil {
+	if err := b.all.unmarshal("0", b, r); err != nil {
 		return &errval{s: `could not unmarshal All Group JSON`, e: err}
 	}
 	b.all.Type = All
@@ -417,14 +445,17 @@ func (b *Bridge) getControls(x context.Context) error {
 	if err != nil || len(r) == 0 {
 		return err
 	}
+	return b.loadControls(r)
+}
+func (b *Bridge) loadControls(r []byte) error {
 	m := make(map[string]json.RawMessage)
-	if err = json.Unmarshal(r, &m); err != nil || len(m) == 0 {
+	if err := json.Unmarshal(r, &m); err != nil || len(m) == 0 {
 		return &errval{s: "could not unmarshal Light JSON", e: err}
 	}
 	b.lights, b.controls = make(map[string]*Light, len(m)), make(map[string]*Control, len(m))
 	for k, v := range m {
 		var d decoder
-		if err = d.unmarshal(k, b, v); err != nil {
+		if err := d.unmarshal(k, b, v); err != nil {
 			return &errval{s: `could not unmarshal Light "` + k + `" JSON`, e: err}
 		}
 		if d.l != nil {
@@ -520,34 +551,32 @@ func ConnectContext(x context.Context, address, key string) (*Bridge, error) {
 	if err != nil {
 		return nil, err
 	}
-	b := &Bridge{
-		ctx: x,
-		client: &http.Client{
-			Timeout: timeoutDefault,
-			Transport: &http.Transport{
-				Proxy:                 http.ProxyFromEnvironment,
-				DialContext:           (&net.Dialer{Timeout: timeoutDefault, KeepAlive: timeoutDefault}).DialContext,
-				IdleConnTimeout:       timeoutDefault,
-				TLSHandshakeTimeout:   timeoutDefault,
-				ExpectContinueTimeout: timeoutDefault,
-				ResponseHeaderTimeout: timeoutDefault,
-			},
-		},
+	b := &Bridge{ctx: x, key: key, client: newClient(u), Timeout: timeoutDefault}
+	if b.base = u.String(); b.base[len(b.base)-1] == '/' {
+		b.base = b.base[:len(b.base)-1]
+	}
+	b.addr = b.base + "/api/" + key
+	return b, nil
+}
+func newClient(u *url.URL) *http.Client {
+	c := &http.Client{
 		Timeout: timeoutDefault,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: timeoutDefault, KeepAlive: timeoutDefault}).DialContext,
+			IdleConnTimeout:       timeoutDefault,
+			TLSHandshakeTimeout:   timeoutDefault,
+			ExpectContinueTimeout: timeoutDefault,
+			ResponseHeaderTimeout: timeoutDefault,
+		},
 	}
 	if len(u.Scheme) == 0 {
 		u.Scheme = "https"
 	}
 	if u.Path = ""; u.Scheme == "https" {
-		b.client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		c.Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
-	s := u.String()
-	if s[len(s)-1] != '/' {
-		b.addr = s + "/api/" + key
-	} else {
-		b.addr = s + "api/" + key
-	}
-	return b, nil
+	return c
 }
 
 // GroupsContext will attempt to get a list of the Groups on the Bridge.
@@ -607,6 +636,12 @@ func (b *Bridge) ControlsContext(x context.Context) (map[string]*Control, error)
 	return c, nil
 }
 func (b *Bridge) request(x context.Context, m, u string, d []byte) ([]byte, error) {
+	b.auth.RLock()
+	a := b.addr
+	b.auth.RUnlock()
+	return b.do(x, m, a+u, d)
+}
+func (b *Bridge) do(x context.Context, m, u string, d []byte) ([]byte, error) {
 	var (
 		t = x
 		f = func() {}
@@ -615,12 +650,12 @@ func (b *Bridge) request(x context.Context, m, u string, d []byte) ([]byte, erro
 		t, f = context.WithTimeout(x, b.Timeout)
 	}
 	var (
-		v, _   = http.NewRequestWithContext(t, m, b.addr+u, bytes.NewReader(d))
+		v, _   = http.NewRequestWithContext(t, m, u, bytes.NewReader(d))
 		r, err = b.client.Do(v)
 	)
 	if err != nil {
 		f()
-		return nil, &errval{s: `could not access "` + b.addr + u + `"`, e: err}
+		return nil, &errval{s: `could not access "` + u + `"`, e: err}
 	}
 	var o response
 	for j := json.NewDecoder(r.Body); j.More(); {
@@ -0,0 +1,137 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testKey     = "testkey"
+	testGamut   = `"colorgamut":[[0.6915,0.3083],[0.17,0.7],[0.1532,0.0475]]`
+	testLight   = `{"name":"%s","uniqueid":"00:17:88:01:00:00:00:0%s-0b","type":"Extended color light","modelid":"LCT015","productname":"Hue color lamp","state":{"on":false,"bri":1,"hue":0,"sat":0,"xy":[0.3,0.3],"ct":153,"alert":"none","effect":"none","colormode":"xy","reachable":true},"capabilities":{"control":{` + testGamut + `,"ct":{"min":153,"max":500}}}}`
+	testSensors = `{"1":{"name":"Daylight","type":"Daylight","modelid":"PHDL00","state":{"daylight":null,"lastupdated":"none"},"config":{"on":true}}}`
+)
+
+type testServer struct {
+	*httptest.Server
+	lock sync.Mutex
+	r    map[string]string
+	q    map[string][]string
+	h    map[string]chan struct{}
+	l    []string
+}
+
+func testLights(n ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`"` + n[i] + `":` + strings.NewReplacer("%s", n[i]).Replace(testLight))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+func newTestBridge(t *testing.T, r map[string]string) (*Bridge, *testServer) {
+	s := &testServer{r: r}
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	b, err := ConnectContext(context.Background(), s.URL, testKey)
+	if err != nil {
+		t.Fatalf("ConnectContext failed: %s", err)
+	}
+	return b, s
+}
+func (s *testServer) set(k, v string) {
+	s.lock.Lock()
+	s.r[k] = v
+	s.lock.Unlock()
+}
+func (s *testServer) queue(k string, v ...string) {
+	// Each request to the route returns the next value, the last value is
+	// returned once the others have been used.
+	s.lock.Lock()
+	if s.q == nil {
+		s.q = make(map[string][]string)
+	}
+	s.q[k] = v
+	s.lock.Unlock()
+}
+func (s *testServer) hold(k string) chan struct{} {
+	// Requests to the route send on the returned channel once received and
+	// wait for a receive on it before responding.
+	c := make(chan struct{})
+	s.lock.Lock()
+	if s.h == nil {
+		s.h = make(map[string]chan struct{})
+	}
+	s.h[k] = c
+	s.lock.Unlock()
+	return c
+}
+func (s *testServer) requests() []string {
+	s.lock.Lock()
+	r := append([]string(nil), s.l...)
+	s.lock.Unlock()
+	return r
+}
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		d, _ = io.ReadAll(r.Body)
+		p    = r.URL.Path
+	)
+	if strings.HasPrefix(p, "/api/") {
+		// Remove the access key, so the routes work with any key.
+		if i := strings.IndexByte(p[5:], '/'); i > 0 {
+			p = p[5+i:]
+		} else {
+			p = ""
+		}
+	}
+	k := r.Method + " " + p
+	s.lock.Lock()
+	if r.Method != http.MethodGet {
+		s.l = append(s.l, k+" "+string(d))
+	}
+	v, ok := s.r[k]
+	if q := s.q[k]; len(q) > 0 {
+		if v, ok = q[0], true; len(q) > 1 {
+			s.q[k] = q[1:]
+		}
+	}
+	h := s.h[k]
+	s.lock.Unlock()
+	if h != nil {
+		h <- struct{}{}
+		<-h
+	}
+	switch {
+	case ok:
+		io.WriteString(w, v)
+	case r.Method == http.MethodGet:
+		http.NotFound(w, r)
+	default:
+		io.WriteString(w, `[{"success":{}}]`)
+	}
+}
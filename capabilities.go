@@ -0,0 +1,138 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// ErrLimit is an error returned when a resource cannot be created as the Bridge
+// has reached the limit for that resource type. Returned errors wrap this error
+// and can be checked with 'errors.Is'.
+var ErrLimit = &errval{s: `Bridge resource limit reached`}
+
+// Capacity is the number of resources of a type that are available (not used)
+// and the total number of resources that the Bridge supports.
+type Capacity struct {
+	Available uint16 `json:"available"`
+	Total     uint16 `json:"total"`
+}
+
+// SensorCapacity is the Sensor Capacity of the Bridge, including the Capacity of
+// each Sensor type.
+type SensorCapacity struct {
+	CLIP Capacity `json:"clip"`
+	ZLL  Capacity `json:"zll"`
+	ZGP  Capacity `json:"zgp"`
+	Capacity
+}
+
+// SceneCapacity is the Scene Capacity of the Bridge, including the Capacity of
+// the Light states stored in all Scenes.
+type SceneCapacity struct {
+	LightStates Capacity `json:"lightstates"`
+	Capacity
+}
+
+// RuleCapacity is the Rule Capacity of the Bridge, including the Capacity of the
+// conditions and actions of all Rules.
+type RuleCapacity struct {
+	Conditions Capacity `json:"conditions"`
+	Actions    Capacity `json:"actions"`
+	Capacity
+}
+
+// StreamCapacity is the Entertainment streaming Capacity of the Bridge. The
+// 'Channels' value is the maximum number of channels of a stream.
+type StreamCapacity struct {
+	Capacity
+	Channels uint16 `json:"channels"`
+}
+
+// Capabilities is the resource Capacity of the Bridge, which is used to
+// determine if a resource can be created.
+type Capabilities struct {
+	Sensors       SensorCapacity `json:"sensors"`
+	Rules         RuleCapacity   `json:"rules"`
+	Scenes        SceneCapacity  `json:"scenes"`
+	Streaming     StreamCapacity `json:"streaming"`
+	Timezones     []string       `json:"-"`
+	Lights        Capacity       `json:"lights"`
+	Groups        Capacity       `json:"groups"`
+	Schedules     Capacity       `json:"schedules"`
+	ResourceLinks Capacity       `json:"resourcelinks"`
+}
+
+// Used returns the number of resources that are used.
+func (c Capacity) Used() uint16 {
+	if c.Available > c.Total {
+		return 0
+	}
+	return c.Total - c.Available
+}
+
+// Capabilities returns the current resource Capabilities of the Bridge. This
+// value is not cached, as it changes as resources are created and removed.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge or if the Bridge API version is older than 1.15.0.
+func (b *Bridge) Capabilities(x context.Context) (*Capabilities, error) {
+	if err := b.require(x, "1.15.0", "Capabilities"); err != nil {
+		return nil, err
+	}
+	return b.capabilities(x)
+}
+func (b *Bridge) capabilities(x context.Context) (*Capabilities, error) {
+	r, err := b.request(x, http.MethodGet, "/capabilities", nil)
+	if err != nil {
+		return nil, err
+	}
+	var c Capabilities
+	if err = json.Unmarshal(r, &c); err != nil {
+		return nil, &errval{s: `could not unmarshal Capabilities JSON`, e: err}
+	}
+	var t struct {
+		Timezones struct {
+			Values []string `json:"values"`
+		} `json:"timezones"`
+	}
+	if json.Unmarshal(r, &t) == nil {
+		c.Timezones = t.Timezones.Values
+	}
+	return &c, nil
+}
+func (b *Bridge) limit(x context.Context, s string, n uint16, f func(*Capabilities) Capacity) error {
+	// Bridges that do not support the capabilities resource are not checked, as
+	// the create request will fail on its own if the limit is reached.
+	c, err := b.Capabilities(x)
+	if errors.Is(err, ErrUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if v := f(c); v.Available < n {
+		return &errval{
+			s: s + ` limit reached (` + strconv.Itoa(int(v.Used())) + ` of ` + strconv.Itoa(int(v.Total)) + ` used)`,
+			e: ErrLimit,
+		}
+	}
+	return nil
+}
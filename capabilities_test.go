@@ -0,0 +1,59 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLimit(t *testing.T) {
+	var (
+		x = context.Background()
+		f = func(c *Capabilities) Capacity { return c.Groups }
+		v = []struct {
+			r     map[string]string
+			limit bool
+			err   bool
+		}{
+			{r: map[string]string{
+				"GET /config":       `{"name":"Bridge","apiversion":"1.50.0"}`,
+				"GET /capabilities": `{"groups":{"available":1,"total":64}}`,
+			}},
+			{r: map[string]string{
+				"GET /config":       `{"name":"Bridge","apiversion":"1.50.0"}`,
+				"GET /capabilities": `{"groups":{"available":0,"total":64}}`,
+			}, limit: true, err: true},
+			{r: map[string]string{"GET /config": `{"name":"Bridge","apiversion":"1.14.0"}`}},
+			{r: map[string]string{
+				"GET /config":       `{"name":"Bridge","apiversion":"1.50.0"}`,
+				"GET /capabilities": `[{"error":{"type":1,"address":"/capabilities","description":"unauthorized user"}}]`,
+			}, err: true},
+			{r: map[string]string{}, err: true},
+		}
+	)
+	for i, c := range v {
+		b, _ := newTestBridge(t, c.r)
+		err := b.limit(x, "Group", 1, f)
+		if (err != nil) != c.err {
+			t.Errorf("limit %d: unexpected error value: %v", i, err)
+		}
+		if errors.Is(err, ErrLimit) != c.limit {
+			t.Errorf("limit %d: error %v does not match ErrLimit", i, err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Clip is a client for the Hue CLIP API v2, which uses the "/clip/v2/resource"
+// paths and identifies resources by UUIDs instead of the numerical IDs of the
+// v1 API.
+//
+// A Clip client can be used alongside a v1 Bridge, which allows for migrating
+// to the v2 API incrementally. Use the 'Clip' function on a Bridge to get a
+// Clip client that shares the same connection and key.
+type Clip struct {
+	ctx    context.Context
+	client *http.Client
+
+	addr, key string
+	Timeout   time.Duration
+}
+type clipError struct {
+	Description string `json:"description"`
+}
+type clipResponse struct {
+	Errors []clipError     `json:"errors"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Clip returns a CLIP API v2 client that uses the same address, key and
+// connection settings as this Bridge.
+func (b *Bridge) Clip() *Clip {
+	return &Clip{ctx: b.ctx, client: b.client, addr: b.base, key: b.Key(), Timeout: b.Timeout}
+}
+
+// ConnectClip returns a CLIP API v2 client based on the specified address/hostname
+// and access key string.
+//
+// The access key is the same key that is used for the v1 API. See 'Connect' for
+// more info.
+func ConnectClip(address, key string) (*Clip, error) {
+	return ConnectClipContext(context.Background(), address, key)
+}
+
+// ConnectClipContext returns a CLIP API v2 client based on the specified
+// address/hostname and access key string. This function allows specifying the
+// base context to be used.
+//
+// The access key is the same key that is used for the v1 API. See 'Connect' for
+// more info.
+func ConnectClipContext(x context.Context, address, key string) (*Clip, error) {
+	u, err := parse(address)
+	if err != nil {
+		return nil, err
+	}
+	c := &Clip{ctx: x, key: key, client: newClient(u), Timeout: timeoutDefault}
+	if c.addr = u.String(); c.addr[len(c.addr)-1] == '/' {
+		c.addr = c.addr[:len(c.addr)-1]
+	}
+	return c, nil
+}
+
+// Delete will delete the resource of the specified type and ID.
+func (c *Clip) Delete(x context.Context, t, id string) error {
+	_, err := c.request(x, http.MethodDelete, "/clip/v2/resource/"+t+"/"+id, nil)
+	return err
+}
+
+// Get will retrieve the resource of the specified type and ID and will unmarshal
+// it into the supplied value, which should be a pointer to one of the Clip*
+// resource structs.
+func (c *Clip) Get(x context.Context, t, id string, v interface{}) error {
+	r, err := c.request(x, http.MethodGet, "/clip/v2/resource/"+t+"/"+id, nil)
+	if err != nil {
+		return err
+	}
+	var d []json.RawMessage
+	if err = json.Unmarshal(r, &d); err != nil {
+		return &errval{s: `could not parse response JSON`, e: err}
+	}
+	if len(d) == 0 {
+		return ErrNotFound
+	}
+	return json.Unmarshal(d[0], v)
+}
+
+// List will retrieve all the resources of the specified type and will unmarshal
+// them into the supplied value, which should be a pointer to a slice of one of
+// the Clip* resource structs.
+//
+// An empty type will return all resources, which can be unmarshaled into a
+// slice of 'ClipResource' structs.
+func (c *Clip) List(x context.Context, t string, v interface{}) error {
+	u := "/clip/v2/resource"
+	if len(t) > 0 {
+		u += "/" + t
+	}
+	r, err := c.request(x, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(r, v)
+}
+
+// Put will change the resource of the specified type and ID using the supplied
+// value, which will be marshaled to JSON. Only the values that are set will be
+// changed.
+func (c *Clip) Put(x context.Context, t, id string, v interface{}) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = c.request(x, http.MethodPut, "/clip/v2/resource/"+t+"/"+id, d)
+	return err
+}
+
+// Post will create a new resource of the specified type using the supplied value,
+// which will be marshaled to JSON.
+//
+// This function returns a reference to the created resource.
+func (c *Clip) Post(x context.Context, t string, v interface{}) (ClipRef, error) {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return ClipRef{}, err
+	}
+	r, err := c.request(x, http.MethodPost, "/clip/v2/resource/"+t, d)
+	if err != nil {
+		return ClipRef{}, err
+	}
+	var o []ClipRef
+	if err = json.Unmarshal(r, &o); err != nil {
+		return ClipRef{}, &errval{s: `could not parse response JSON`, e: err}
+	}
+	if len(o) == 0 {
+		return ClipRef{}, &errval{s: `response did not contain a resource reference`}
+	}
+	return o[0], nil
+}
+func (c *Clip) request(x context.Context, m, u string, d []byte) (json.RawMessage, error) {
+	var (
+		t = x
+		f = func() {}
+	)
+	if c.Timeout > 0 {
+		t, f = context.WithTimeout(x, c.Timeout)
+	}
+	v, err := http.NewRequestWithContext(t, m, c.addr+u, bytes.NewReader(d))
+	if err != nil {
+		f()
+		return nil, err
+	}
+	if v.Header.Set("hue-application-key", c.key); len(d) > 0 {
+		v.Header.Set("Content-Type", "application/json")
+	}
+	r, err := c.client.Do(v)
+	if err != nil {
+		f()
+		return nil, &errval{s: `could not access "` + c.addr + u + `"`, e: err}
+	}
+	var o clipResponse
+	err = json.NewDecoder(r.Body).Decode(&o)
+	r.Body.Close()
+	if f(); len(o.Errors) > 0 {
+		e := o.Errors[0].Description
+		for i := 1; i < len(o.Errors); i++ {
+			e += ", " + o.Errors[i].Description
+		}
+		return nil, &errval{s: `error returned from "` + u + `": ` + e}
+	}
+	if r.StatusCode >= 300 {
+		return nil, &errval{s: `error returned from "` + u + `": ` + r.Status}
+	}
+	if err != nil {
+		return nil, &errval{s: `could not parse response JSON`, e: err}
+	}
+	return o.Data, nil
+}
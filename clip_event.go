@@ -0,0 +1,379 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var errDenied = &errval{s: `access to the event stream was denied`}
+
+// ClipEvent is an event received from the CLIP API v2 event stream.
+type ClipEvent struct {
+	Time time.Time
+	// Err is the error that occurred while reading the event stream or parsing
+	// an event. This is only set when the Type is "error".
+	Err error
+	// Resources contains the resources included in the event. Each entry will be
+	// a pointer to the matching Clip* struct for the resource type, such as
+	// '*ClipLight' or '*ClipButton'. Unknown resource types will be a pointer to
+	// a 'ClipResource' struct.
+	//
+	// Update events only contain the values that were changed.
+	Resources []interface{}
+	ID        string
+	// Type is the type of event and will be "add", "update", "delete" or "error".
+	Type string
+}
+
+// EventStream is a consumer of the CLIP API v2 event stream, which receives
+// changes from the Bridge as they happen, instead of polling for them.
+//
+// Use the 'EventStream' function on a Clip client to create one.
+type EventStream struct {
+	clip    *Clip
+	buttons map[string]ClipButton
+
+	// Bridge, if not nil, is a v1 Bridge that will have the state of it's cached
+	// Lights, Controls and Sensors updated in place when events are received.
+	//
+	// The updates are made while holding the Bridge lock, before the event is
+	// passed to the 'Run' function. Sensor 'Values' maps are replaced instead of
+	// changed, but the Light and Control states are changed in place, so they
+	// should only be read from inside the 'Run' function while it is running.
+	//
+	// If nil (the default), the Bridge is not updated.
+	Bridge *Bridge
+	// LastID is the ID of the last event received. This is sent when reconnecting
+	// so no events are missed.
+	LastID string
+	// Retry is the time to wait before reconnecting to the event stream. If zero,
+	// one second is used.
+	Retry time.Duration
+}
+type clipEventJSON struct {
+	Time time.Time         `json:"creationtime"`
+	ID   string            `json:"id"`
+	Type string            `json:"type"`
+	Data []json.RawMessage `json:"data"`
+}
+
+// EventStream returns a new EventStream that can be used to receive events from
+// the CLIP API v2 event stream.
+func (c *Clip) EventStream() *EventStream {
+	return &EventStream{clip: c}
+}
+
+// UnmarshalJSON fulfils the JSON Unmarshaler interface.
+func (e *ClipEvent) UnmarshalJSON(d []byte) error {
+	var v clipEventJSON
+	if err := json.Unmarshal(d, &v); err != nil {
+		return err
+	}
+	e.Time, e.ID, e.Type = v.Time, v.ID, v.Type
+	e.Resources = make([]interface{}, 0, len(v.Data))
+	for i := range v.Data {
+		var r ClipResource
+		if err := json.Unmarshal(v.Data[i], &r); err != nil {
+			return err
+		}
+		var o interface{}
+		switch r.Type {
+		case ClipTypeLight:
+			o = new(ClipLight)
+		case ClipTypeGroupedLight:
+			o = new(ClipGroupedLight)
+		case ClipTypeRoom, ClipTypeZone:
+			o = new(ClipGroup)
+		case ClipTypeDevice:
+			o = new(ClipDevice)
+		case ClipTypeScene:
+			o = new(ClipScene)
+		case ClipTypeMotion:
+			o = new(ClipMotion)
+		case ClipTypeButton:
+			o = new(ClipButton)
+		case ClipTypeTemperature:
+			o = new(ClipTemperature)
+		case ClipTypeLightLevel:
+			o = new(ClipLightLevel)
+		case ClipTypeDevicePower:
+			o = new(ClipDevicePower)
+		case ClipTypeConnectivity:
+			o = new(ClipConnectivity)
+		default:
+			e.Resources = append(e.Resources, &r)
+			continue
+		}
+		if err := json.Unmarshal(v.Data[i], o); err != nil {
+			return err
+		}
+		e.Resources = append(e.Resources, o)
+	}
+	return nil
+}
+
+// Run will connect to the event stream and call the supplied function for every
+// event received. If the connection is lost, it will be reconnected after the
+// 'Retry' duration and resumed from the last event received.
+//
+// Any errors that occur while connecting, reading the stream or parsing events
+// are passed to the supplied function as an "error" event with the 'Err' value
+// set.
+//
+// This function blocks until the supplied Context is canceled, which will return
+// nil. An error is only returned if the Bridge rejects the access key.
+func (s *EventStream) Run(x context.Context, f func(ClipEvent)) error {
+	r := s.Retry
+	if r <= 0 {
+		r = time.Second
+	}
+	if s.Bridge != nil && s.buttons == nil {
+		// Button update events may not contain the button number or v1 ID, which
+		// are needed for the v1 button event codes.
+		if v, err := s.clip.Buttons(x); err == nil {
+			s.buttons = make(map[string]ClipButton, len(v))
+			for i := range v {
+				s.buttons[v[i].ID] = v[i]
+			}
+		}
+	}
+	for {
+		err := s.read(x, f)
+		if err == errDenied {
+			return err
+		}
+		if err != nil && f != nil && x.Err() == nil {
+			f(ClipEvent{Type: "error", Time: time.Now(), Err: err})
+		}
+		t := time.NewTimer(r)
+		select {
+		case <-x.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+func (s *EventStream) read(x context.Context, f func(ClipEvent)) error {
+	v, err := http.NewRequestWithContext(x, http.MethodGet, s.clip.addr+"/eventstream/clip/v2", nil)
+	if err != nil {
+		return err
+	}
+	v.Header.Set("Accept", "text/event-stream")
+	if v.Header.Set("hue-application-key", s.clip.key); len(s.LastID) > 0 {
+		v.Header.Set("Last-Event-ID", s.LastID)
+	}
+	// The shared client has a timeout set, which would close the stream, so a
+	// client using the same Transport without a timeout is used instead.
+	r, err := (&http.Client{Transport: s.clip.client.Transport}).Do(v)
+	if err != nil {
+		return &errval{s: `could not access "` + s.clip.addr + `/eventstream/clip/v2"`, e: err}
+	}
+	if r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden {
+		r.Body.Close()
+		return errDenied
+	}
+	if r.StatusCode != http.StatusOK {
+		r.Body.Close()
+		return &errval{s: `event stream returned "` + r.Status + `"`}
+	}
+	var (
+		b = bufio.NewReader(r.Body)
+		d strings.Builder
+		i string
+	)
+	for {
+		l, err := b.ReadString('\n')
+		if err != nil {
+			r.Body.Close()
+			return &errval{s: `could not read event stream`, e: err}
+		}
+		if l = strings.TrimRight(l, "\r\n"); len(l) == 0 {
+			// A blank line dispatches the event that was read.
+			if d.Len() > 0 {
+				s.dispatch(i, d.String(), f)
+			}
+			d.Reset()
+			continue
+		}
+		if l[0] == ':' {
+			continue
+		}
+		k, n := l, ""
+		if p := strings.IndexByte(l, ':'); p > 0 {
+			if k, n = l[:p], l[p+1:]; len(n) > 0 && n[0] == ' ' {
+				n = n[1:]
+			}
+		}
+		switch k {
+		case "id":
+			i = n
+		case "data":
+			if d.Len() > 0 {
+				d.WriteByte('\n')
+			}
+			d.WriteString(n)
+		}
+	}
+}
+func (s *EventStream) dispatch(i, d string, f func(ClipEvent)) {
+	if len(i) > 0 {
+		s.LastID = i
+	}
+	var e []ClipEvent
+	if err := json.Unmarshal([]byte(d), &e); err != nil {
+		if f != nil {
+			f(ClipEvent{Type: "error", Time: time.Now(), ID: i, Err: &errval{s: `could not parse event JSON`, e: err}})
+		}
+		return
+	}
+	for n := range e {
+		if s.Bridge != nil {
+			s.Bridge.update(e[n], s.buttons)
+		}
+		if f != nil {
+			f(e[n])
+		}
+	}
+}
+func (b *Bridge) update(e ClipEvent, m map[string]ClipButton) {
+	if e.Type != "update" {
+		return
+	}
+	b.lock.Lock()
+	for _, r := range e.Resources {
+		switch v := r.(type) {
+		case *ClipLight:
+			if c := b.controlByV1(v.IDv1); c != nil {
+				c.updateClip(v)
+			}
+		case *ClipMotion:
+			if s := b.sensorByV1(v.IDv1); s != nil {
+				s.setValue("presence", v.Motion.Motion, e.Time)
+			}
+		case *ClipTemperature:
+			if s := b.sensorByV1(v.IDv1); s != nil {
+				s.setValue("temperature", math.Round(v.Temperature.Temperature*100), e.Time)
+			}
+		case *ClipLightLevel:
+			if s := b.sensorByV1(v.IDv1); s != nil {
+				s.setValue("lightlevel", float64(v.Light.Level), e.Time)
+			}
+		case *ClipButton:
+			i, n := v.IDv1, v.Metadata.Control
+			if o, ok := m[v.ID]; ok {
+				if len(i) == 0 {
+					i = o.IDv1
+				}
+				if n == 0 {
+					n = o.Metadata.Control
+				}
+			}
+			s := b.sensorByV1(i)
+			if s == nil {
+				continue
+			}
+			if c, ok := buttonCode(v.Button.LastEvent); ok && n > 0 {
+				s.setValue("buttonevent", float64(int(n)*1000+c), e.Time)
+			}
+		case *ClipDevicePower:
+			if s := b.sensorByV1(v.IDv1); s != nil && s.config.Battery != nil {
+				*s.config.Battery = v.PowerState.Level
+			}
+		case *ClipConnectivity:
+			k := v.Status == "connected"
+			if c := b.controlByV1(v.IDv1); c != nil {
+				c.state.Reachable, c.known.Reachable = k, k
+			}
+			if s := b.sensorByV1(v.IDv1); s != nil {
+				s.config.Reachable = k
+			}
+		}
+	}
+	b.lock.Unlock()
+}
+func (s *Sensor) setValue(k string, v interface{}, t time.Time) {
+	// The Values map is replaced instead of changed, so callers reading the
+	// previous map are not affected.
+	m := make(map[string]interface{}, len(s.Values)+1)
+	for n, o := range s.Values {
+		m[n] = o
+	}
+	m[k], s.Values, s.Updated.Time = v, m, t
+}
+func buttonCode(s string) (int, bool) {
+	switch s {
+	case "initial_press":
+		return 0, true
+	case "repeat", "long_press":
+		return 1, true
+	case "short_release":
+		return 2, true
+	case "long_release":
+		return 3, true
+	}
+	return 0, false
+}
+func (c *Control) updateClip(v *ClipLight) {
+	if v.On != nil {
+		c.state.On, c.known.On = v.On.On, v.On.On
+	}
+	if v.Dimming != nil {
+		n := uint8(math.Round(v.Dimming.Brightness * 2.54))
+		if n == 0 {
+			n = 1
+		}
+		c.state.Brightness, c.known.Brightness = n, n
+	}
+	if v.Color != nil {
+		var s controlState
+		s.XY = point{float32(v.Color.XY.X), float32(v.Color.XY.Y)}
+		c.state.merge(s, maskXY)
+		c.known.merge(s, maskXY)
+	}
+	if v.ColorTemperature != nil && v.ColorTemperature.Mirek != nil && v.ColorTemperature.Valid {
+		var s controlState
+		s.Temperature = *v.ColorTemperature.Mirek
+		c.state.merge(s, maskTemperature)
+		c.known.merge(s, maskTemperature)
+	}
+}
+func (b *Bridge) controlByV1(s string) *Control {
+	if !strings.HasPrefix(s, "/lights/") {
+		return nil
+	}
+	if l, ok := b.lights[s[8:]]; ok {
+		return &l.Control
+	}
+	return b.controls[s[8:]]
+}
+func (b *Bridge) sensorByV1(s string) *Sensor {
+	if !strings.HasPrefix(s, "/sensors/") {
+		return nil
+	}
+	if v, ok := b.sensors[s[9:]]; ok && v.Values != nil {
+		return v
+	}
+	return nil
+}
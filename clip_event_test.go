@@ -0,0 +1,141 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testEvents = `: hi
+
+id: 1:0
+data: [{"creationtime":"2023-01-01T00:00:00Z","id":"e1","type":"update",
+data: "data":[{"id":"l1","id_v1":"/lights/1","type":"light","on":{"on":true}}]}]
+
+event: message
+id: 2:0
+data: [{"creationtime":"2023-01-01T00:00:01Z","id":"e2","type":"add","data":[{"id":"x1","type":"unknown"}]}]
+
+id: 3:0
+data: [{bad
+
+`
+
+func TestEventStream(t *testing.T) {
+	var (
+		h    []string
+		lock sync.Mutex
+		q    = make(chan struct{})
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/eventstream/clip/v2" || r.Header.Get("hue-application-key") != testKey {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		lock.Lock()
+		h = append(h, r.Header.Get("Last-Event-ID"))
+		n := len(h)
+		lock.Unlock()
+		if n == 1 {
+			io.WriteString(w, testEvents)
+			return
+		}
+		io.WriteString(w, "id: 4:0\ndata: [{\"creationtime\":\"2023-01-01T00:00:02Z\",\"id\":\"e3\",\"type\":\"delete\",\"data\":[]}]\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-q:
+		}
+	}))
+	defer s.Close()
+	defer close(q)
+	b, _ := newTestBridge(t, map[string]string{"GET /lights": testLights("1"), "GET /sensors": testSensors})
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	c, err := ConnectClip(s.URL, testKey)
+	if err != nil {
+		t.Fatalf("ConnectClip failed: %s", err)
+	}
+	var (
+		e    []ClipEvent
+		x, f = context.WithTimeout(context.Background(), 5*time.Second)
+		v    = c.EventStream()
+	)
+	defer f()
+	v.Bridge, v.Retry = b, 10*time.Millisecond
+	// Set the Button cache, so 'Run' does not request it from the server.
+	v.buttons = map[string]ClipButton{}
+	err = v.Run(x, func(n ClipEvent) {
+		if e = append(e, n); n.ID == "e3" {
+			f()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+	if len(e) != 5 {
+		t.Fatalf("Run received %d events, want 5: %+v", len(e), e)
+	}
+	for i, n := range []string{"update", "add", "error", "error", "delete"} {
+		if e[i].Type != n {
+			t.Errorf("event %d has type %q, want %q", i, e[i].Type, n)
+		}
+		if (n == "error") != (e[i].Err != nil) {
+			t.Errorf("event %d has an unexpected Err value: %v", i, e[i].Err)
+		}
+	}
+	if len(e[0].Resources) != 1 {
+		t.Fatalf("event 0 has %d resources, want 1", len(e[0].Resources))
+	}
+	if r, ok := e[0].Resources[0].(*ClipLight); !ok || r.On == nil || !r.On.On {
+		t.Errorf("event 0 has an unexpected resource: %+v", e[0].Resources[0])
+	}
+	if _, ok := e[1].Resources[0].(*ClipResource); !ok {
+		t.Errorf("event 1 has an unexpected resource: %+v", e[1].Resources[0])
+	}
+	if !l["1"].IsOn() {
+		t.Errorf("Light 1 was not updated by the event")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(h) != 2 || h[0] != "" || h[1] != "3:0" {
+		t.Errorf("server received Last-Event-ID values %q, want [\"\" \"3:0\"]", h)
+	}
+	if v.LastID != "4:0" {
+		t.Errorf("LastID is %q, want \"4:0\"", v.LastID)
+	}
+}
+func TestEventStreamDenied(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer s.Close()
+	c, err := ConnectClip(s.URL, testKey)
+	if err != nil {
+		t.Fatalf("ConnectClip failed: %s", err)
+	}
+	if err = c.EventStream().Run(context.Background(), nil); err != errDenied {
+		t.Fatalf("Run returned %v, want %v", err, errDenied)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ClipGraph is a map of all the CLIP API v2 resources on the Bridge, which links
+// each physical device to the services it provides and each v1 Light, Control,
+// Sensor, Group and Scene to the matching v2 resource.
+//
+// This can be used to migrate stored v1 IDs to v2 IDs, or to use v2-only
+// features on v1 objects.
+type ClipGraph struct {
+	devices map[string]*ClipDevice
+	byID    map[string]ClipResource
+	byV1    map[string][]ClipResource
+}
+
+// Graph will retrieve all the CLIP API v2 resources from the Bridge and build a
+// ClipGraph from them.
+func (c *Clip) Graph(x context.Context) (*ClipGraph, error) {
+	var r []json.RawMessage
+	if err := c.List(x, "", &r); err != nil {
+		return nil, err
+	}
+	g := &ClipGraph{
+		devices: make(map[string]*ClipDevice),
+		byID:    make(map[string]ClipResource, len(r)),
+		byV1:    make(map[string][]ClipResource),
+	}
+	for i := range r {
+		var v ClipResource
+		if err := json.Unmarshal(r[i], &v); err != nil {
+			return nil, &errval{s: `could not unmarshal resource JSON`, e: err}
+		}
+		if v.Type == ClipTypeDevice {
+			d := new(ClipDevice)
+			if err := json.Unmarshal(r[i], d); err != nil {
+				return nil, &errval{s: `could not unmarshal Device "` + v.ID + `" JSON`, e: err}
+			}
+			g.devices[v.ID] = d
+		}
+		if g.byID[v.ID] = v; len(v.IDv1) > 0 {
+			g.byV1[v.IDv1] = append(g.byV1[v.IDv1], v)
+		}
+	}
+	return g, nil
+}
+
+// Devices returns all the physical devices in the ClipGraph.
+func (g *ClipGraph) Devices() []*ClipDevice {
+	r := make([]*ClipDevice, 0, len(g.devices))
+	for _, v := range g.devices {
+		r = append(r, v)
+	}
+	return r
+}
+
+// Resource returns the resource with the specified v2 ID.
+func (g *ClipGraph) Resource(id string) (ClipResource, bool) {
+	r, ok := g.byID[id]
+	return r, ok
+}
+
+// ByV1 returns all the v2 resources that have the specified v1 resource path,
+// such as "/lights/5". Multiple resources may share the same v1 path, such as a
+// light and it's zigbee connectivity service.
+func (g *ClipGraph) ByV1(p string) []ClipResource {
+	return g.byV1[p]
+}
+
+// Light returns the v2 light resource for the specified v1 Light.
+func (g *ClipGraph) Light(l *Light) (ClipResource, bool) {
+	return g.find("/lights/"+l.ID, ClipTypeLight)
+}
+
+// Control returns the v2 light resource for the specified v1 Control.
+func (g *ClipGraph) Control(c *Control) (ClipResource, bool) {
+	return g.find("/lights/"+c.ID, ClipTypeLight)
+}
+
+// Sensor returns the v2 sensor resource (motion, temperature, light_level,
+// button, etc.) for the specified v1 Sensor.
+func (g *ClipGraph) Sensor(s *Sensor) (ClipResource, bool) {
+	return g.find(
+		"/sensors/"+s.ID, ClipTypeMotion, ClipTypeTemperature, ClipTypeLightLevel, ClipTypeButton,
+		"relative_rotary", "geofence_client", "behavior_instance", ClipTypeDevicePower,
+	)
+}
+
+// Group returns the v2 room, zone or entertainment configuration resource for
+// the specified v1 Group.
+func (g *ClipGraph) Group(v *Group) (ClipResource, bool) {
+	return g.find("/groups/"+v.ID, ClipTypeRoom, ClipTypeZone, ClipTypeEntertainmentConfiguration, "bridge_home")
+}
+
+// GroupedLight returns the v2 grouped light resource that controls the Lights
+// for the specified v1 Group.
+func (g *ClipGraph) GroupedLight(v *Group) (ClipResource, bool) {
+	return g.find("/groups/"+v.ID, ClipTypeGroupedLight)
+}
+
+// Scene returns the v2 scene resource for the specified v1 Scene ID.
+func (g *ClipGraph) Scene(id string) (ClipResource, bool) {
+	return g.find("/scenes/"+id, ClipTypeScene)
+}
+func (g *ClipGraph) find(p string, t ...string) (ClipResource, bool) {
+	r := g.byV1[p]
+	for i := range t {
+		for n := range r {
+			if r[n].Type == t[i] {
+				return r[n], true
+			}
+		}
+	}
+	return ClipResource{}, false
+}
+
+// Device returns the physical device that owns the resource with the specified
+// v2 ID. The owners of the resource are followed until a device is found.
+//
+// This function returns nil if the resource is not owned by a device, such as
+// rooms and zones.
+func (g *ClipGraph) Device(id string) *ClipDevice {
+	for i := 0; i < 8; i++ {
+		if d, ok := g.devices[id]; ok {
+			return d
+		}
+		r, ok := g.byID[id]
+		if !ok || r.Owner == nil {
+			return nil
+		}
+		id = r.Owner.ID
+	}
+	return nil
+}
+
+// Services returns all the resources provided by the specified device.
+func (g *ClipGraph) Services(d *ClipDevice) []ClipResource {
+	r := make([]ClipResource, 0, len(d.Services))
+	for i := range d.Services {
+		if v, ok := g.byID[d.Services[i].ID]; ok {
+			r = append(r, v)
+		}
+	}
+	return r
+}
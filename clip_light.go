@@ -0,0 +1,167 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"time"
+)
+
+// Effects supported by CLIP API v2 lights. Not all lights support all effects,
+// the 'Values' list in the ClipEffects of a ClipLight contains the supported
+// effects.
+const (
+	ClipEffectNone      = "no_effect"
+	ClipEffectCandle    = "candle"
+	ClipEffectFire      = "fire"
+	ClipEffectPrism     = "prism"
+	ClipEffectSparkle   = "sparkle"
+	ClipEffectOpal      = "opal"
+	ClipEffectGlisten   = "glisten"
+	ClipEffectColorLoop = "colorloop"
+)
+
+// Timed effects supported by CLIP API v2 lights. Timed effects run for the
+// specified duration and then stop.
+const (
+	ClipTimedEffectNone    = "no_effect"
+	ClipTimedEffectSunrise = "sunrise"
+	ClipTimedEffectSunset  = "sunset"
+)
+
+// Signals supported by CLIP API v2 lights. Signals are used to identify a light
+// and run for the specified duration.
+//
+// The 'ClipSignalOnOffColor' signal requires one color and the
+// 'ClipSignalAlternating' signal requires two colors.
+const (
+	ClipSignalNone        = "no_signal"
+	ClipSignalOnOff       = "on_off"
+	ClipSignalOnOffColor  = "on_off_color"
+	ClipSignalAlternating = "alternating"
+)
+
+// ClipGradientPoint is a single color point of a gradient.
+type ClipGradientPoint struct {
+	Color ClipColor `json:"color"`
+}
+
+// ClipGradient is the gradient of a CLIP API v2 light that supports multiple
+// colors, such as a gradient light strip. The 'Capable' value is the maximum
+// number of points the light supports.
+type ClipGradient struct {
+	Points  []ClipGradientPoint `json:"points"`
+	Modes   []string            `json:"mode_values,omitempty"`
+	Mode    string              `json:"mode,omitempty"`
+	Capable uint8               `json:"points_capable,omitempty"`
+}
+
+// ClipEffects is the dynamic effect state of a CLIP API v2 light.
+type ClipEffects struct {
+	Values []string `json:"effect_values,omitempty"`
+	Effect string   `json:"effect,omitempty"`
+	Status string   `json:"status,omitempty"`
+}
+
+// ClipTimedEffects is the timed effect state of a CLIP API v2 light. The
+// 'Duration' value is in milliseconds.
+type ClipTimedEffects struct {
+	Values   []string `json:"effect_values,omitempty"`
+	Effect   string   `json:"effect,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Duration uint32   `json:"duration,omitempty"`
+}
+
+// ClipSignalStatus is the currently active signal of a CLIP API v2 light.
+type ClipSignalStatus struct {
+	End    time.Time `json:"estimated_end"`
+	Signal string    `json:"signal"`
+}
+
+// ClipSignaling is the signaling state of a CLIP API v2 light. The 'Duration'
+// value is in milliseconds.
+type ClipSignaling struct {
+	Status   *ClipSignalStatus `json:"status,omitempty"`
+	Colors   []ClipColor       `json:"colors,omitempty"`
+	Values   []string          `json:"signal_values,omitempty"`
+	Signal   string            `json:"signal,omitempty"`
+	Duration uint32            `json:"duration,omitempty"`
+}
+
+// SetGradient will set the gradient of the CLIP API v2 light with the specified
+// ID to the supplied colors. Colors may be any value accepted by 'ParseColor'.
+func (c *Clip) SetGradient(x context.Context, id string, colors ...string) error {
+	p, err := clipColors(colors)
+	if err != nil {
+		return err
+	}
+	g := &ClipGradient{Points: make([]ClipGradientPoint, len(p))}
+	for i := range p {
+		g.Points[i].Color = p[i]
+	}
+	return c.UpdateLight(x, id, ClipLightUpdate{Gradient: g})
+}
+
+// SetEffect will start the specified effect on the CLIP API v2 light with the
+// specified ID. The effect 'ClipEffectNone' stops any running effect.
+//
+// The speed is between zero and one and is ignored if zero.
+func (c *Clip) SetEffect(x context.Context, id, effect string, speed float64) error {
+	u := ClipLightUpdate{Effects: &ClipEffects{Effect: effect}}
+	if speed > 0 {
+		if speed > 1 {
+			speed = 1
+		}
+		u.Dynamics = &ClipDynamics{Speed: speed}
+	}
+	return c.UpdateLight(x, id, u)
+}
+
+// SetTimedEffect will start the specified timed effect, such as a sunrise, on the
+// CLIP API v2 light with the specified ID. The effect will run for the supplied
+// duration.
+func (c *Clip) SetTimedEffect(x context.Context, id, effect string, d time.Duration) error {
+	return c.UpdateLight(x, id, ClipLightUpdate{
+		TimedEffects: &ClipTimedEffects{Effect: effect, Duration: uint32(d / time.Millisecond)},
+	})
+}
+
+// Signal will start the specified signal on the CLIP API v2 light with the
+// specified ID for the supplied duration. Colors may be any value accepted by
+// 'ParseColor'.
+func (c *Clip) Signal(x context.Context, id, signal string, d time.Duration, colors ...string) error {
+	p, err := clipColors(colors)
+	if err != nil {
+		return err
+	}
+	return c.UpdateLight(x, id, ClipLightUpdate{
+		Signaling: &ClipSignaling{Signal: signal, Duration: uint32(d / time.Millisecond), Colors: p},
+	})
+}
+func clipColors(s []string) ([]ClipColor, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	r := make([]ClipColor, len(s))
+	for i := range s {
+		x, y, err := xyFromString(*fullGamut, s[i])
+		if err != nil {
+			return nil, err
+		}
+		r[i].XY = ClipXY{X: float64(x), Y: float64(y)}
+	}
+	return r, nil
+}
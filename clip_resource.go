@@ -0,0 +1,440 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"time"
+)
+
+// CLIP API v2 resource type names.
+const (
+	ClipTypeLight        = "light"
+	ClipTypeGroupedLight = "grouped_light"
+	ClipTypeRoom         = "room"
+	ClipTypeZone         = "zone"
+	ClipTypeDevice       = "device"
+	ClipTypeScene        = "scene"
+	ClipTypeMotion       = "motion"
+	ClipTypeButton       = "button"
+	ClipTypeTemperature  = "temperature"
+	ClipTypeLightLevel   = "light_level"
+	ClipTypeDevicePower  = "device_power"
+	ClipTypeConnectivity = "zigbee_connectivity"
+
+	ClipTypeEntertainmentConfiguration = "entertainment_configuration"
+)
+
+// ClipRef is a reference to a CLIP API v2 resource.
+type ClipRef struct {
+	ID   string `json:"rid"`
+	Type string `json:"rtype"`
+}
+
+// ClipResource contains the values that are shared by all CLIP API v2 resources.
+type ClipResource struct {
+	Owner *ClipRef `json:"owner,omitempty"`
+
+	ID   string `json:"id"`
+	IDv1 string `json:"id_v1,omitempty"`
+	Type string `json:"type"`
+}
+
+// ClipMetadata is the descriptive information of a CLIP API v2 resource.
+type ClipMetadata struct {
+	Name      string `json:"name,omitempty"`
+	Archetype string `json:"archetype,omitempty"`
+	Function  string `json:"function,omitempty"`
+}
+
+// ClipOn is the power state of a CLIP API v2 light or grouped light.
+type ClipOn struct {
+	On bool `json:"on"`
+}
+
+// ClipDimming is the brightness of a CLIP API v2 light or grouped light as a
+// percentage.
+type ClipDimming struct {
+	Brightness  float64 `json:"brightness"`
+	MinDimLevel float64 `json:"min_dim_level,omitempty"`
+}
+
+// ClipXY is a color on the CIE 1931 XY axis.
+type ClipXY struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ClipGamut is the color gamut supported by a CLIP API v2 light.
+type ClipGamut struct {
+	Red   ClipXY `json:"red"`
+	Green ClipXY `json:"green"`
+	Blue  ClipXY `json:"blue"`
+}
+
+// ClipColor is the XY color of a CLIP API v2 light.
+type ClipColor struct {
+	Gamut     *ClipGamut `json:"gamut,omitempty"`
+	XY        ClipXY     `json:"xy"`
+	GamutType string     `json:"gamut_type,omitempty"`
+}
+
+// ClipMirekSchema is the supported color temperature range of a CLIP API v2
+// light in mireds.
+type ClipMirekSchema struct {
+	Min uint16 `json:"mirek_minimum"`
+	Max uint16 `json:"mirek_maximum"`
+}
+
+// ClipColorTemperature is the color temperature of a CLIP API v2 light. The
+// 'Mirek' value is nil if the light is not in the color temperature mode.
+type ClipColorTemperature struct {
+	Mirek  *uint16          `json:"mirek,omitempty"`
+	Schema *ClipMirekSchema `json:"mirek_schema,omitempty"`
+	Valid  bool             `json:"mirek_valid,omitempty"`
+}
+
+// ClipDynamics is the transition state of a CLIP API v2 light.
+type ClipDynamics struct {
+	Status   string  `json:"status,omitempty"`
+	Speed    float64 `json:"speed,omitempty"`
+	Duration uint32  `json:"duration,omitempty"`
+}
+
+// ClipAlert contains the alert actions supported by a CLIP API v2 light.
+type ClipAlert struct {
+	Values []string `json:"action_values,omitempty"`
+	Action string   `json:"action,omitempty"`
+}
+
+// ClipLight is a CLIP API v2 light resource.
+type ClipLight struct {
+	On               *ClipOn               `json:"on,omitempty"`
+	Dimming          *ClipDimming          `json:"dimming,omitempty"`
+	Color            *ClipColor            `json:"color,omitempty"`
+	ColorTemperature *ClipColorTemperature `json:"color_temperature,omitempty"`
+	Dynamics         *ClipDynamics         `json:"dynamics,omitempty"`
+	Gradient         *ClipGradient         `json:"gradient,omitempty"`
+	Effects          *ClipEffects          `json:"effects,omitempty"`
+	TimedEffects     *ClipTimedEffects     `json:"timed_effects,omitempty"`
+	Signaling        *ClipSignaling        `json:"signaling,omitempty"`
+	Alert            *ClipAlert            `json:"alert,omitempty"`
+	Metadata         ClipMetadata          `json:"metadata"`
+	Mode             string                `json:"mode,omitempty"`
+	ClipResource
+}
+
+// ClipLightUpdate contains the values that can be changed on a CLIP API v2
+// light or grouped light. Only the values that are not nil are changed.
+type ClipLightUpdate struct {
+	On               *ClipOn               `json:"on,omitempty"`
+	Dimming          *ClipDimming          `json:"dimming,omitempty"`
+	Color            *ClipColor            `json:"color,omitempty"`
+	ColorTemperature *ClipColorTemperature `json:"color_temperature,omitempty"`
+	Dynamics         *ClipDynamics         `json:"dynamics,omitempty"`
+	Gradient         *ClipGradient         `json:"gradient,omitempty"`
+	Effects          *ClipEffects          `json:"effects,omitempty"`
+	TimedEffects     *ClipTimedEffects     `json:"timed_effects,omitempty"`
+	Signaling        *ClipSignaling        `json:"signaling,omitempty"`
+	Alert            *ClipAlert            `json:"alert,omitempty"`
+	Metadata         *ClipMetadata         `json:"metadata,omitempty"`
+}
+
+// ClipGroupedLight is a CLIP API v2 grouped light resource, which controls all
+// the lights in a room or zone.
+type ClipGroupedLight struct {
+	On      *ClipOn      `json:"on,omitempty"`
+	Dimming *ClipDimming `json:"dimming,omitempty"`
+	Alert   *ClipAlert   `json:"alert,omitempty"`
+	ClipResource
+}
+
+// ClipGroup is a CLIP API v2 room or zone resource. The children of a room are
+// devices, while the children of a zone are lights.
+type ClipGroup struct {
+	Children []ClipRef    `json:"children"`
+	Services []ClipRef    `json:"services"`
+	Metadata ClipMetadata `json:"metadata"`
+	ClipResource
+}
+
+// ClipPosition is the position of a light or channel in an entertainment area.
+// Each value is between -1 and 1.
+type ClipPosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// ClipChannelMember is a light segment that is part of an entertainment channel.
+type ClipChannelMember struct {
+	Service ClipRef `json:"service"`
+	Index   uint8   `json:"index"`
+}
+
+// ClipChannel is a single entertainment channel of a CLIP API v2 entertainment
+// configuration. The 'ID' value is the channel ID used when streaming.
+type ClipChannel struct {
+	Members  []ClipChannelMember `json:"members"`
+	Position ClipPosition        `json:"position"`
+	ID       uint8               `json:"channel_id"`
+}
+
+// ClipServiceLocation is the location of an entertainment service (a light) in
+// an entertainment configuration.
+type ClipServiceLocation struct {
+	Service   ClipRef        `json:"service"`
+	Positions []ClipPosition `json:"positions"`
+}
+
+// ClipLocations contains the locations of all the entertainment services in an
+// entertainment configuration.
+type ClipLocations struct {
+	Services []ClipServiceLocation `json:"service_locations"`
+}
+
+// ClipStreamProxy is the proxy settings of a CLIP API v2 entertainment
+// configuration. The 'Mode' value is "auto" or "manual".
+type ClipStreamProxy struct {
+	Node ClipRef `json:"node"`
+	Mode string  `json:"mode"`
+}
+
+// ClipEntertainmentConfiguration is a CLIP API v2 entertainment configuration
+// resource, which describes an entertainment area. The 'Streamer' value is set
+// to the application that owns the stream when the 'Status' is "active".
+type ClipEntertainmentConfiguration struct {
+	Streamer  *ClipRef        `json:"active_streamer,omitempty"`
+	Proxy     ClipStreamProxy `json:"stream_proxy"`
+	Metadata  ClipMetadata    `json:"metadata"`
+	Locations ClipLocations   `json:"locations"`
+	Channels  []ClipChannel   `json:"channels"`
+	Status    string          `json:"status"`
+	Kind      string          `json:"configuration_type"`
+	ClipResource
+}
+
+// ClipEntertainmentUpdate contains the values that can be changed on a CLIP API
+// v2 entertainment configuration. Only the values that are not nil are changed.
+type ClipEntertainmentUpdate struct {
+	Metadata  *ClipMetadata    `json:"metadata,omitempty"`
+	Locations *ClipLocations   `json:"locations,omitempty"`
+	Proxy     *ClipStreamProxy `json:"stream_proxy,omitempty"`
+	Kind      string           `json:"configuration_type,omitempty"`
+}
+
+// ClipProductData is the product information of a CLIP API v2 device.
+type ClipProductData struct {
+	Model        string `json:"model_id"`
+	Manufacturer string `json:"manufacturer_name"`
+	Product      string `json:"product_name"`
+	Archetype    string `json:"product_archetype"`
+	Software     string `json:"software_version"`
+	Certified    bool   `json:"certified"`
+}
+
+// ClipDevice is a CLIP API v2 device resource, which represents a physical
+// device and references the services (lights, sensors, etc.) it provides.
+type ClipDevice struct {
+	Services    []ClipRef       `json:"services"`
+	Metadata    ClipMetadata    `json:"metadata"`
+	ProductData ClipProductData `json:"product_data"`
+	ClipResource
+}
+
+// ClipSceneAction is the state a CLIP API v2 scene will set a light to.
+type ClipSceneAction struct {
+	Target ClipRef         `json:"target"`
+	Action ClipLightUpdate `json:"action"`
+}
+
+// ClipScene is a CLIP API v2 scene resource.
+type ClipScene struct {
+	Actions  []ClipSceneAction `json:"actions"`
+	Metadata ClipMetadata      `json:"metadata"`
+	Group    ClipRef           `json:"group"`
+	Speed    float64           `json:"speed,omitempty"`
+	ClipResource
+}
+
+// ClipMotion is a CLIP API v2 motion sensor resource.
+type ClipMotion struct {
+	Motion struct {
+		Motion bool `json:"motion"`
+		Valid  bool `json:"motion_valid"`
+	} `json:"motion"`
+	Enabled bool `json:"enabled"`
+	ClipResource
+}
+
+// ClipButton is a CLIP API v2 button resource.
+type ClipButton struct {
+	Button struct {
+		Report *struct {
+			Updated time.Time `json:"updated"`
+			Event   string    `json:"event"`
+		} `json:"button_report,omitempty"`
+		LastEvent string `json:"last_event,omitempty"`
+	} `json:"button"`
+	Metadata struct {
+		Control uint8 `json:"control_id"`
+	} `json:"metadata"`
+	ClipResource
+}
+
+// ClipTemperature is a CLIP API v2 temperature sensor resource. The temperature
+// is in degrees Celsius.
+type ClipTemperature struct {
+	Temperature struct {
+		Temperature float64 `json:"temperature"`
+		Valid       bool    `json:"temperature_valid"`
+	} `json:"temperature"`
+	Enabled bool `json:"enabled"`
+	ClipResource
+}
+
+// ClipLightLevel is a CLIP API v2 light level sensor resource. The light level
+// is 10000*log10(lux)+1.
+type ClipLightLevel struct {
+	Light struct {
+		Level uint32 `json:"light_level"`
+		Valid bool   `json:"light_level_valid"`
+	} `json:"light"`
+	Enabled bool `json:"enabled"`
+	ClipResource
+}
+
+// ClipDevicePower is a CLIP API v2 device power resource, which reports the
+// battery state of a device.
+type ClipDevicePower struct {
+	PowerState struct {
+		State string `json:"battery_state"`
+		Level uint8  `json:"battery_level"`
+	} `json:"power_state"`
+	ClipResource
+}
+
+// ClipConnectivity is a CLIP API v2 zigbee connectivity resource, which reports
+// if a device can be reached by the Bridge. The 'Status' value will be
+// "connected" when the device is reachable.
+type ClipConnectivity struct {
+	Status string `json:"status"`
+	ClipResource
+}
+
+// Lights returns all the CLIP API v2 light resources.
+func (c *Clip) Lights(x context.Context) ([]ClipLight, error) {
+	var r []ClipLight
+	err := c.List(x, ClipTypeLight, &r)
+	return r, err
+}
+
+// GroupedLights returns all the CLIP API v2 grouped light resources.
+func (c *Clip) GroupedLights(x context.Context) ([]ClipGroupedLight, error) {
+	var r []ClipGroupedLight
+	err := c.List(x, ClipTypeGroupedLight, &r)
+	return r, err
+}
+
+// Rooms returns all the CLIP API v2 room resources.
+func (c *Clip) Rooms(x context.Context) ([]ClipGroup, error) {
+	var r []ClipGroup
+	err := c.List(x, ClipTypeRoom, &r)
+	return r, err
+}
+
+// Zones returns all the CLIP API v2 zone resources.
+func (c *Clip) Zones(x context.Context) ([]ClipGroup, error) {
+	var r []ClipGroup
+	err := c.List(x, ClipTypeZone, &r)
+	return r, err
+}
+
+// Devices returns all the CLIP API v2 device resources.
+func (c *Clip) Devices(x context.Context) ([]ClipDevice, error) {
+	var r []ClipDevice
+	err := c.List(x, ClipTypeDevice, &r)
+	return r, err
+}
+
+// Scenes returns all the CLIP API v2 scene resources.
+func (c *Clip) Scenes(x context.Context) ([]ClipScene, error) {
+	var r []ClipScene
+	err := c.List(x, ClipTypeScene, &r)
+	return r, err
+}
+
+// Motions returns all the CLIP API v2 motion sensor resources.
+func (c *Clip) Motions(x context.Context) ([]ClipMotion, error) {
+	var r []ClipMotion
+	err := c.List(x, ClipTypeMotion, &r)
+	return r, err
+}
+
+// Buttons returns all the CLIP API v2 button resources.
+func (c *Clip) Buttons(x context.Context) ([]ClipButton, error) {
+	var r []ClipButton
+	err := c.List(x, ClipTypeButton, &r)
+	return r, err
+}
+
+// Temperatures returns all the CLIP API v2 temperature sensor resources.
+func (c *Clip) Temperatures(x context.Context) ([]ClipTemperature, error) {
+	var r []ClipTemperature
+	err := c.List(x, ClipTypeTemperature, &r)
+	return r, err
+}
+
+// LightLevels returns all the CLIP API v2 light level sensor resources.
+func (c *Clip) LightLevels(x context.Context) ([]ClipLightLevel, error) {
+	var r []ClipLightLevel
+	err := c.List(x, ClipTypeLightLevel, &r)
+	return r, err
+}
+
+// DevicePowers returns all the CLIP API v2 device power resources.
+func (c *Clip) DevicePowers(x context.Context) ([]ClipDevicePower, error) {
+	var r []ClipDevicePower
+	err := c.List(x, ClipTypeDevicePower, &r)
+	return r, err
+}
+
+// EntertainmentConfigurations returns all the CLIP API v2 entertainment
+// configuration resources.
+func (c *Clip) EntertainmentConfigurations(x context.Context) ([]ClipEntertainmentConfiguration, error) {
+	var r []ClipEntertainmentConfiguration
+	err := c.List(x, ClipTypeEntertainmentConfiguration, &r)
+	return r, err
+}
+
+// UpdateLight will change the CLIP API v2 light with the specified ID using the
+// values set in the supplied ClipLightUpdate.
+func (c *Clip) UpdateLight(x context.Context, id string, u ClipLightUpdate) error {
+	return c.Put(x, ClipTypeLight, id, u)
+}
+
+// UpdateGroupedLight will change the CLIP API v2 grouped light with the specified
+// ID using the values set in the supplied ClipLightUpdate.
+func (c *Clip) UpdateGroupedLight(x context.Context, id string, u ClipLightUpdate) error {
+	return c.Put(x, ClipTypeGroupedLight, id, u)
+}
+
+// UpdateEntertainmentConfiguration will change the CLIP API v2 entertainment
+// configuration with the specified ID using the values set in the supplied
+// ClipEntertainmentUpdate.
+func (c *Clip) UpdateEntertainmentConfiguration(x context.Context, id string, u ClipEntertainmentUpdate) error {
+	return c.Put(x, ClipTypeEntertainmentConfiguration, id, u)
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestClipLightUpdateMarshal(t *testing.T) {
+	m := uint16(300)
+	for _, v := range []struct {
+		u ClipLightUpdate
+		s string
+	}{
+		{ClipLightUpdate{ColorTemperature: &ClipColorTemperature{}}, `{"color_temperature":{}}`},
+		{ClipLightUpdate{ColorTemperature: &ClipColorTemperature{Mirek: &m}}, `{"color_temperature":{"mirek":300}}`},
+	} {
+		b, err := json.Marshal(v.u)
+		if err != nil {
+			t.Fatalf("Marshal failed: %s", err)
+		}
+		if string(b) != v.s {
+			t.Errorf("Marshal = %s, want %s", b, v.s)
+		}
+	}
+}
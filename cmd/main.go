@@ -16,10 +16,10 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/iDigitalFlame/hue"
@@ -35,21 +35,38 @@ Usage:
     -list
         List all Groups and Lights that can be targeted. If this is supplied
         no other optional arguments are parsed.
+    -keys
+        List all the access keys on the Bridge with their names, creation
+        and last used dates. The key in use is marked with "*". If this is
+        supplied no other optional arguments are parsed.
+    -prune  X(s|m|h)
+        Remove all access keys that have not been used within the duration
+        string. The key in use is never removed. If this is supplied no other
+        optional arguments are parsed.
     -on
         Turn on a Light or Control. Takes precedence over "-off".
     -off
         Turn off a Light or Control. Cannot be used with "-on".
+    -color  <color>
+        Set the Light color using a CSS color string. This can be a color name
+        ("coral"), a 3, 4, 6 or 8 digit hex value ("#f80"), or the CSS "rgb()"
+        or "hsl()" functions ("rgb(255 128 0)" or "hsl(30,100%,50%)").
     -hex    #XXXXXX
-        Set the Light color using a Hex value. This value must be in "#XXXXXX"
-        format (6 characters 2 R, 2 G, 2 B), starting with "#".
+        Alias of "-color", kept for compatibility.
     -rgb    XXX,XXX,XXX
-        Set the Light color using a RGB comma seperated value. This value must
+        Alias of "-color" using a RGB comma seperated value. This value must
         include the other values, even if they are zero.
     -sat    0 - 255
         Set the Light color saturation using a value from 0 (no saturation) to
         255 (high saturation).
-    -temp   0 - 65355
-        Set the Light temperature as a value from 0 (cooler) to 65355 (warmer).
+    -temp   153 - 500
+        Set the Light temperature in mireds as a value from 153 (cooler) to
+        500 (warmer). Values outside of the Light's range are clamped to the
+        Light's range.
+    -kelvin 2000 - 6500
+        Set the Light temperature in Kelvin as a value from 2000 (warmer) to
+        6500 (cooler). Values outside of the Light's range are clamped to the
+        Light's range. Takes precedence over "-temp".
     -bright 0 - 255
         Set the Light brightness as a value from 0 (off) to 255 (full brightness).
     -trans  X(s|m|h)
@@ -75,10 +92,11 @@ var version = "unknown"
 
 func main() {
 	var (
-		trans                       time.Duration
-		bright, sat, temp           int
-		on, off, list, ver          bool
+		trans, prune                time.Duration
+		bright, sat, temp, kelvin   int
+		on, off, list, ver, keys    bool
 		target, hex, rgb, addr, key string
+		color                       string
 		f                           = flag.NewFlagSet("huectl", flag.ExitOnError)
 	)
 	f.StringVar(&key, "k", "", "")
@@ -87,11 +105,15 @@ func main() {
 	f.BoolVar(&on, "on", false, "")
 	f.BoolVar(&off, "off", false, "")
 	f.BoolVar(&list, "list", false, "")
+	f.BoolVar(&keys, "keys", false, "")
+	f.DurationVar(&prune, "prune", 0, "")
 	f.BoolVar(&ver, "V", false, "")
+	f.StringVar(&color, "color", "", "")
 	f.StringVar(&hex, "hex", "", "")
 	f.StringVar(&rgb, "rgb", "", "")
 	f.IntVar(&sat, "sat", -1, "")
 	f.IntVar(&temp, "temp", -1, "")
+	f.IntVar(&kelvin, "kelvin", -1, "")
 	f.IntVar(&bright, "bright", -1, "")
 	f.DurationVar(&trans, "trans", 0, "")
 	f.Usage = func() {
@@ -116,32 +138,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	if !list && len(target) == 0 {
+	if !list && !keys && prune <= 0 && len(target) == 0 {
 		os.Stderr.WriteString(`The target value "-t" cannot be empty!` + "\n")
 		os.Exit(1)
 	}
 
-	var (
-		r, g, b uint64
-		err     error
-	)
-	if len(rgb) > 0 {
-		v := strings.Split(strings.TrimSpace(rgb), ",")
-		if len(v) != 3 {
-			os.Stderr.WriteString(`Invalid RGB value "` + rgb + `"!` + "\n")
-			os.Exit(1)
-		}
-		_ = v[2]
-		if r, err = strconv.ParseUint(strings.TrimSpace(v[0]), 10, 8); err != nil {
-			os.Stderr.WriteString(`Invalid RGB "R" value "` + rgb + `": ` + err.Error() + "!\n")
-			os.Exit(1)
-		}
-		if g, err = strconv.ParseUint(strings.TrimSpace(v[1]), 10, 8); err != nil {
-			os.Stderr.WriteString(`Invalid RGB "G" value "` + rgb + `": ` + err.Error() + "!\n")
-			os.Exit(1)
-		}
-		if b, err = strconv.ParseUint(strings.TrimSpace(v[2]), 10, 8); err != nil {
-			os.Stderr.WriteString(`Invalid RGB "B" value "` + rgb + `": ` + err.Error() + "!\n")
+	switch {
+	case len(color) > 0:
+	case len(hex) > 0:
+		color = hex
+	case len(rgb) > 0:
+		color = "rgb(" + rgb + ")"
+	}
+	if len(color) > 0 {
+		if _, _, _, err := hue.ParseColor(color); err != nil {
+			os.Stderr.WriteString(`Invalid color value "` + color + `": ` + err.Error() + "!\n")
 			os.Exit(1)
 		}
 	}
@@ -152,6 +163,44 @@ func main() {
 		os.Exit(1)
 	}
 
+	if keys || prune > 0 {
+		c, err := x.Config()
+		if err != nil {
+			os.Stderr.WriteString(`Could not get the Bridge config from "` + addr + `": ` + err.Error() + "!\n")
+			os.Exit(1)
+		}
+		if keys {
+			os.Stdout.WriteString("Keys List\n================\n")
+			for _, v := range c.Keys() {
+				if v.Key == key {
+					os.Stdout.WriteString("* ")
+				}
+				os.Stdout.WriteString(
+					"[" + v.Key + "] " + v.Name + ": Created " + v.Created.Format(time.RFC3339) +
+						" Last Used " + v.LastUsed.Format(time.RFC3339) + "\n",
+				)
+			}
+			os.Exit(0)
+		}
+		var (
+			d = time.Now().Add(-prune)
+			n int
+		)
+		for _, v := range c.Keys() {
+			if v.Key == key || v.LastUsed.After(d) {
+				continue
+			}
+			if err = x.DeleteKey(context.Background(), v.Key); err != nil {
+				os.Stderr.WriteString(`Could not remove key "` + v.Name + `": ` + err.Error() + "!\n")
+				continue
+			}
+			os.Stdout.WriteString(`Removed key "` + v.Name + `" [` + v.Key + "]\n")
+			n++
+		}
+		os.Stdout.WriteString("Removed " + strconv.Itoa(n) + " keys.\n")
+		os.Exit(0)
+	}
+
 	if list {
 		g, err := x.Groups()
 		if err != nil {
@@ -195,60 +244,47 @@ func main() {
 		os.Exit(1)
 	}
 
-	for _, e := range i.Controls {
-		if e.Manual = true; on {
-			e.SetOn(true)
-		}
-		if !on && off {
-			e.SetOn(false)
-		}
-		if err = e.Update(); err != nil {
-			break
+	var v hue.LightState
+	if on {
+		v.SetOn(true)
+	} else if off {
+		v.SetOn(false)
+	}
+	if bright > -1 {
+		v.SetBrightness(uint8(bright))
+	}
+	if sat > -1 {
+		v.SetSaturation(uint8(sat))
+	}
+	if kelvin > -1 {
+		v.SetKelvin(uint16(kelvin))
+	} else if temp > -1 {
+		v.SetTemperature(uint16(temp))
+	}
+	if len(color) > 0 {
+		if err = v.SetColor(color); err != nil {
+			os.Stderr.WriteString(`Invalid color value "` + color + `": ` + err.Error() + "!\n")
+			os.Exit(1)
 		}
 	}
+	v.SetTransition(trans)
+
+	// Use a Batch, so the state is sent with a single Group command when
+	// possible, instead of a command for each Light.
+	q := x.Batch()
+	q.Group(v, i)
+	o, err := q.Apply()
 	if err != nil {
-		os.Stderr.WriteString("Error changing controls: " + err.Error() + "!\n")
+		os.Stderr.WriteString("Error changing requested lights: " + err.Error() + "!\n")
 		os.Exit(1)
 	}
-
-	for _, e := range i.Lights {
-		if e.Manual = true; on {
-			e.SetOn(true)
-		}
-		if !on && off {
-			e.SetOn(false)
-		}
-		if bright > -1 {
-			e.SetBrightness(uint8(bright))
-		}
-		if e.SetTransition(trans); !e.IsColor() {
-			if err = e.Update(); err != nil {
-				break
-			}
-			continue
-		}
-		if sat > -1 {
-			e.SetSaturation(uint8(sat))
-		}
-		if temp > -1 {
-			e.SetTemperature(uint16(temp))
-		}
-		if len(rgb) > 0 {
-			if err = e.SetRGB(uint8(r), uint8(g), uint8(b)); err != nil {
-				break
-			}
-		}
-		if len(hex) > 0 {
-			if err = e.SetHex(hex); err != nil {
-				break
-			}
-		}
-		if err = e.Update(); err != nil {
-			break
+	for _, e := range o {
+		if e.Err != nil {
+			os.Stderr.WriteString(`Error changing "` + e.ID + `": ` + e.Err.Error() + "!\n")
+			err = e.Err
 		}
 	}
 	if err != nil {
-		os.Stderr.WriteString("Error changing requested lights: " + err.Error() + "!\n")
 		os.Exit(1)
 	}
 	os.Exit(0)
@@ -18,7 +18,6 @@ package hue
 import (
 	"encoding/json"
 	"math"
-	"strconv"
 )
 
 var defaultGamut = &gamut{
@@ -26,11 +25,43 @@ var defaultGamut = &gamut{
 	Blue:  point{0.17, 0.7},
 	Green: point{0.153, 0.048},
 }
+var defaultRange = &ctRange{Min: 153, Max: 500}
+
+// fullGamut covers every possible XY value, which allows for converting colors
+// without clamping them to a specific Light's gamut.
+var fullGamut = &gamut{
+	Red:   point{1, 0},
+	Blue:  point{0, 1},
+	Green: point{0, 0},
+}
 
 type gamut struct {
 	Red, Blue, Green point
 }
 type point [2]float32
+type ctRange struct {
+	Min uint16 `json:"min"`
+	Max uint16 `json:"max"`
+}
+
+func mireds(k uint16) uint16 {
+	if k == 0 {
+		return 0xFFFF
+	}
+	if v := 1000000 / uint32(k); v < 0xFFFF {
+		return uint16(v)
+	}
+	return 0xFFFF
+}
+func (r ctRange) clamp(t uint16) uint16 {
+	if t < r.Min {
+		return r.Min
+	}
+	if r.Max > 0 && t > r.Max {
+		return r.Max
+	}
+	return t
+}
 
 func (g gamut) reachable(x, y float32) bool {
 	var (
@@ -75,28 +106,73 @@ func closest(a, b point, x, y float32) (float32, float32) {
 	return a[0] + j[0]*k, a[1] + j[1]*k
 }
 func xyFromHex(c gamut, s string) (float32, float32, error) {
-	if len(s) < 6 || len(s) > 7 || (len(s) == 7 && s[0] != '#') {
-		return 0, 0, &errval{s: `hex value "` + s + `" is invalid`}
+	r, g, b, err := rgbFromHex(s)
+	if err != nil {
+		return 0, 0, err
 	}
-	i := 0
-	if s[0] == '#' {
-		i++
+	x, y := xyFromRGB(c, r, g, b)
+	return x, y, nil
+}
+func xyFromString(c gamut, s string) (float32, float32, error) {
+	r, g, b, err := ParseColor(s)
+	if err != nil {
+		return 0, 0, err
 	}
-	var (
-		r, g, b uint64
-		err     error
-	)
-	if r, err = strconv.ParseUint(s[i:i+2], 16, 16); err != nil {
-		return 0, 0, &errval{s: `hex red value is invalid`, e: err}
+	x, y := xyFromRGB(c, r, g, b)
+	return x, y, nil
+}
+func xyFromTemperature(c gamut, t uint16) (float32, float32) {
+	// Planckian locus approximation by Kim et al. which is valid from 1667K to
+	// 25000K, values outside of that are clamped to the edges.
+	k := 1000000.0 / float64(t)
+	if t == 0 || k > 25000 {
+		k = 25000
+	} else if k < 1667 {
+		k = 1667
 	}
-	if g, err = strconv.ParseUint(s[i+2:i+4], 16, 16); err != nil {
-		return 0, 0, &errval{s: `hex green value is invalid`, e: err}
+	var x, y float64
+	if k <= 4000 {
+		x = -0.2661239e9/(k*k*k) - 0.2343589e6/(k*k) + 0.8776956e3/k + 0.179910
+	} else {
+		x = -3.0258469e9/(k*k*k) + 2.1070379e6/(k*k) + 0.2226347e3/k + 0.240390
 	}
-	if b, err = strconv.ParseUint(s[i+4:i+6], 16, 16); err != nil {
-		return 0, 0, &errval{s: `hex blue value is invalid`, e: err}
+	switch {
+	case k <= 2222:
+		y = -1.1063814*x*x*x - 1.34811020*x*x + 2.18555832*x - 0.20219683
+	case k <= 4000:
+		y = -0.9549476*x*x*x - 1.37418593*x*x + 2.09137015*x - 0.16748867
+	default:
+		y = 3.0817580*x*x*x - 5.87338670*x*x + 3.75112997*x - 0.37001483
 	}
-	x, y := xyFromRGB(c, uint8(r), uint8(g), uint8(b))
-	return x, y, nil
+	if c.reachable(float32(x), float32(y)) {
+		return float32(x), float32(y)
+	}
+	return c.closestPoint(float32(x), float32(y))
+}
+func (g gamut) clamp(x, y float32) (float32, float32) {
+	if g.reachable(x, y) {
+		return x, y
+	}
+	return g.closestPoint(x, y)
+}
+func common(c []gamut, x, y float32) (float32, float32) {
+	// Alternating projections onto each (convex) gamut will converge on a point
+	// that is reachable by all of them. The limit prevents floating point errors
+	// on the gamut edges from looping forever.
+	for n := 0; n < 64; n++ {
+		k := true
+		for i := range c {
+			if c[i].reachable(x, y) {
+				continue
+			}
+			x, y = c[i].closestPoint(x, y)
+			k = false
+		}
+		if k {
+			break
+		}
+	}
+	return x, y
 }
 func (g gamut) closestPoint(x, y float32) (float32, float32) {
 	var (
@@ -184,12 +260,16 @@ func xyFromRGB(c gamut, red, green, blue uint8) (float32, float32) {
 		b = b / 12.92
 	}
 	var (
-		x  = r*0.664511 + g*0.154324 + b*0.162028
-		y  = r*0.283881 + g*0.668433 + b*0.047685
-		z  = r*0.000088 + g*0.072310 + b*0.986039
-		cx = float32(x / (x + y + z))
-		cy = float32(y / (x + y + z))
+		x = r*0.664511 + g*0.154324 + b*0.162028
+		y = r*0.283881 + g*0.668433 + b*0.047685
+		z = r*0.000088 + g*0.072310 + b*0.986039
 	)
+	if x+y+z == 0 {
+		// Black has no chromaticity, so use the D65 white point instead of
+		// dividing by zero. The darkness is set by the brightness value.
+		return c.clamp(0.3127, 0.3290)
+	}
+	cx, cy := float32(x/(x+y+z)), float32(y/(x+y+z))
 	if c.reachable(cx, cy) {
 		return cx, cy
 	}
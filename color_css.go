@@ -0,0 +1,323 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+var names = map[string]uint32{
+	"aliceblue":            0xF0F8FF,
+	"antiquewhite":         0xFAEBD7,
+	"aqua":                 0x00FFFF,
+	"aquamarine":           0x7FFFD4,
+	"azure":                0xF0FFFF,
+	"beige":                0xF5F5DC,
+	"bisque":               0xFFE4C4,
+	"black":                0x000000,
+	"blanchedalmond":       0xFFEBCD,
+	"blue":                 0x0000FF,
+	"blueviolet":           0x8A2BE2,
+	"brown":                0xA52A2A,
+	"burlywood":            0xDEB887,
+	"cadetblue":            0x5F9EA0,
+	"chartreuse":           0x7FFF00,
+	"chocolate":            0xD2691E,
+	"coral":                0xFF7F50,
+	"cornflowerblue":       0x6495ED,
+	"cornsilk":             0xFFF8DC,
+	"crimson":              0xDC143C,
+	"cyan":                 0x00FFFF,
+	"darkblue":             0x00008B,
+	"darkcyan":             0x008B8B,
+	"darkgoldenrod":        0xB8860B,
+	"darkgray":             0xA9A9A9,
+	"darkgreen":            0x006400,
+	"darkgrey":             0xA9A9A9,
+	"darkkhaki":            0xBDB76B,
+	"darkmagenta":          0x8B008B,
+	"darkolivegreen":       0x556B2F,
+	"darkorange":           0xFF8C00,
+	"darkorchid":           0x9932CC,
+	"darkred":              0x8B0000,
+	"darksalmon":           0xE9967A,
+	"darkseagreen":         0x8FBC8F,
+	"darkslateblue":        0x483D8B,
+	"darkslategray":        0x2F4F4F,
+	"darkslategrey":        0x2F4F4F,
+	"darkturquoise":        0x00CED1,
+	"darkviolet":           0x9400D3,
+	"deeppink":             0xFF1493,
+	"deepskyblue":          0x00BFFF,
+	"dimgray":              0x696969,
+	"dimgrey":              0x696969,
+	"dodgerblue":           0x1E90FF,
+	"firebrick":            0xB22222,
+	"floralwhite":          0xFFFAF0,
+	"forestgreen":          0x228B22,
+	"fuchsia":              0xFF00FF,
+	"gainsboro":            0xDCDCDC,
+	"ghostwhite":           0xF8F8FF,
+	"gold":                 0xFFD700,
+	"goldenrod":            0xDAA520,
+	"gray":                 0x808080,
+	"green":                0x008000,
+	"greenyellow":          0xADFF2F,
+	"grey":                 0x808080,
+	"honeydew":             0xF0FFF0,
+	"hotpink":              0xFF69B4,
+	"indianred":            0xCD5C5C,
+	"indigo":               0x4B0082,
+	"ivory":                0xFFFFF0,
+	"khaki":                0xF0E68C,
+	"lavender":             0xE6E6FA,
+	"lavenderblush":        0xFFF0F5,
+	"lawngreen":            0x7CFC00,
+	"lemonchiffon":         0xFFFACD,
+	"lightblue":            0xADD8E6,
+	"lightcoral":           0xF08080,
+	"lightcyan":            0xE0FFFF,
+	"lightgoldenrodyellow": 0xFAFAD2,
+	"lightgray":            0xD3D3D3,
+	"lightgreen":           0x90EE90,
+	"lightgrey":            0xD3D3D3,
+	"lightpink":            0xFFB6C1,
+	"lightsalmon":          0xFFA07A,
+	"lightseagreen":        0x20B2AA,
+	"lightskyblue":         0x87CEFA,
+	"lightslategray":       0x778899,
+	"lightslategrey":       0x778899,
+	"lightsteelblue":       0xB0C4DE,
+	"lightyellow":          0xFFFFE0,
+	"lime":                 0x00FF00,
+	"limegreen":            0x32CD32,
+	"linen":                0xFAF0E6,
+	"magenta":              0xFF00FF,
+	"maroon":               0x800000,
+	"mediumaquamarine":     0x66CDAA,
+	"mediumblue":           0x0000CD,
+	"mediumorchid":         0xBA55D3,
+	"mediumpurple":         0x9370DB,
+	"mediumseagreen":       0x3CB371,
+	"mediumslateblue":      0x7B68EE,
+	"mediumspringgreen":    0x00FA9A,
+	"mediumturquoise":      0x48D1CC,
+	"mediumvioletred":      0xC71585,
+	"midnightblue":         0x191970,
+	"mintcream":            0xF5FFFA,
+	"mistyrose":            0xFFE4E1,
+	"moccasin":             0xFFE4B5,
+	"navajowhite":          0xFFDEAD,
+	"navy":                 0x000080,
+	"oldlace":              0xFDF5E6,
+	"olive":                0x808000,
+	"olivedrab":            0x6B8E23,
+	"orange":               0xFFA500,
+	"orangered":            0xFF4500,
+	"orchid":               0xDA70D6,
+	"palegoldenrod":        0xEEE8AA,
+	"palegreen":            0x98FB98,
+	"paleturquoise":        0xAFEEEE,
+	"palevioletred":        0xDB7093,
+	"papayawhip":           0xFFEFD5,
+	"peachpuff":            0xFFDAB9,
+	"peru":                 0xCD853F,
+	"pink":                 0xFFC0CB,
+	"plum":                 0xDDA0DD,
+	"powderblue":           0xB0E0E6,
+	"purple":               0x800080,
+	"rebeccapurple":        0x663399,
+	"red":                  0xFF0000,
+	"rosybrown":            0xBC8F8F,
+	"royalblue":            0x4169E1,
+	"saddlebrown":          0x8B4513,
+	"salmon":               0xFA8072,
+	"sandybrown":           0xF4A460,
+	"seagreen":             0x2E8B57,
+	"seashell":             0xFFF5EE,
+	"sienna":               0xA0522D,
+	"silver":               0xC0C0C0,
+	"skyblue":              0x87CEEB,
+	"slateblue":            0x6A5ACD,
+	"slategray":            0x708090,
+	"slategrey":            0x708090,
+	"snow":                 0xFFFAFA,
+	"springgreen":          0x00FF7F,
+	"steelblue":            0x4682B4,
+	"tan":                  0xD2B48C,
+	"teal":                 0x008080,
+	"thistle":              0xD8BFD8,
+	"tomato":               0xFF6347,
+	"turquoise":            0x40E0D0,
+	"violet":               0xEE82EE,
+	"wheat":                0xF5DEB3,
+	"white":                0xFFFFFF,
+	"whitesmoke":           0xF5F5F5,
+	"yellow":               0xFFFF00,
+	"yellowgreen":          0x9ACD32,
+}
+
+// ParseColor will attempt to parse the supplied CSS color string into it's RGB
+// values.
+//
+// The following formats are supported:
+//   - CSS named colors, such as "coral" or "rebeccapurple".
+//   - Hex values with 3, 4, 6 or 8 digits, optionally starting with a '#'.
+//   - The CSS "rgb()" and "rgba()" functional notations, using numbers or
+//     percentages and separated by commas or spaces.
+//   - The CSS "hsl()" and "hsla()" functional notations, with an optional hue
+//     unit of "deg", "rad", "grad" or "turn".
+//
+// Any alpha values are accepted but ignored, as Lights cannot be transparent.
+func ParseColor(s string) (uint8, uint8, uint8, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	if len(v) == 0 {
+		return 0, 0, 0, &errval{s: `color value is empty`}
+	}
+	if c, ok := names[v]; ok {
+		return uint8(c >> 16), uint8(c >> 8), uint8(c), nil
+	}
+	if v == "transparent" {
+		return 0, 0, 0, nil
+	}
+	i := strings.IndexByte(v, '(')
+	if i == -1 {
+		return rgbFromHex(v)
+	}
+	if v[len(v)-1] != ')' {
+		return 0, 0, 0, &errval{s: `color value "` + s + `" is invalid`}
+	}
+	a := strings.Fields(strings.NewReplacer(",", " ", "/", " ").Replace(v[i+1 : len(v)-1]))
+	if len(a) != 3 && len(a) != 4 {
+		return 0, 0, 0, &errval{s: `color value "` + s + `" has an invalid number of arguments`}
+	}
+	switch strings.TrimSpace(v[:i]) {
+	case "rgb", "rgba":
+		return rgbFromArgs(s, a)
+	case "hsl", "hsla":
+		return rgbFromHSL(s, a)
+	}
+	return 0, 0, 0, &errval{s: `color value "` + s + `" is an unsupported function`}
+}
+func rgbFromHex(s string) (uint8, uint8, uint8, error) {
+	v := s
+	if len(v) > 0 && v[0] == '#' {
+		v = v[1:]
+	}
+	n, err := strconv.ParseUint(v, 16, 32)
+	if err != nil {
+		return 0, 0, 0, &errval{s: `hex value "` + s + `" is invalid`, e: err}
+	}
+	switch len(v) {
+	case 3:
+		return uint8((n>>8)&0xF) * 0x11, uint8((n>>4)&0xF) * 0x11, uint8(n&0xF) * 0x11, nil
+	case 4:
+		return uint8((n>>12)&0xF) * 0x11, uint8((n>>8)&0xF) * 0x11, uint8((n>>4)&0xF) * 0x11, nil
+	case 6:
+		return uint8(n >> 16), uint8(n >> 8), uint8(n), nil
+	case 8:
+		return uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), nil
+	}
+	return 0, 0, 0, &errval{s: `hex value "` + s + `" is invalid`}
+}
+func number(s string, m float64) (float64, error) {
+	// Parse a CSS number or percentage, percentages are scaled to 'm' and all
+	// values are clamped to [0, m].
+	p := len(s) > 0 && s[len(s)-1] == '%'
+	if p {
+		s = s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p {
+		v = v / 100 * m
+	}
+	if v < 0 {
+		return 0, nil
+	}
+	if v > m {
+		return m, nil
+	}
+	return v, nil
+}
+func rgbFromArgs(s string, a []string) (uint8, uint8, uint8, error) {
+	var c [3]uint8
+	for i := 0; i < 3; i++ {
+		v, err := number(a[i], 255)
+		if err != nil {
+			return 0, 0, 0, &errval{s: `color value "` + s + `" is invalid`, e: err}
+		}
+		c[i] = uint8(math.Round(v))
+	}
+	return c[0], c[1], c[2], nil
+}
+func rgbFromHSL(s string, a []string) (uint8, uint8, uint8, error) {
+	var (
+		h   float64
+		m   = 1.0
+		v   = a[0]
+		err error
+	)
+	switch {
+	case strings.HasSuffix(v, "grad"):
+		v, m = v[:len(v)-4], 0.9
+	case strings.HasSuffix(v, "deg"):
+		v = v[:len(v)-3]
+	case strings.HasSuffix(v, "rad"):
+		v, m = v[:len(v)-3], 180/math.Pi
+	case strings.HasSuffix(v, "turn"):
+		v, m = v[:len(v)-4], 360
+	}
+	if h, err = strconv.ParseFloat(v, 64); err != nil {
+		return 0, 0, 0, &errval{s: `color value "` + s + `" has an invalid hue`, e: err}
+	}
+	if h = math.Mod(h*m, 360); h < 0 {
+		h += 360
+	}
+	t, err := number(strings.TrimSuffix(a[1], "%"), 100)
+	if err != nil {
+		return 0, 0, 0, &errval{s: `color value "` + s + `" has an invalid saturation`, e: err}
+	}
+	l, err := number(strings.TrimSuffix(a[2], "%"), 100)
+	if err != nil {
+		return 0, 0, 0, &errval{s: `color value "` + s + `" has an invalid lightness`, e: err}
+	}
+	var (
+		c       = (1 - math.Abs(2*l/100-1)) * t / 100
+		x       = c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+		o       = l/100 - c/2
+		r, g, b float64
+	)
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return uint8(math.Round((r + o) * 255)), uint8(math.Round((g + o) * 255)), uint8(math.Round((b + o) * 255)), nil
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	v := []struct {
+		s       string
+		r, g, b uint8
+		err     bool
+	}{
+		{s: "coral", r: 0xFF, g: 0x7F, b: 0x50},
+		{s: " RebeccaPurple ", r: 0x66, g: 0x33, b: 0x99},
+		{s: "black"},
+		{s: "transparent"},
+		{s: "#F80", r: 0xFF, g: 0x88},
+		{s: "f80c", r: 0xFF, g: 0x88},
+		{s: "#00FF7F", g: 0xFF, b: 0x7F},
+		{s: "00ff7f80", g: 0xFF, b: 0x7F},
+		{s: "rgb(255, 0, 128)", r: 0xFF, b: 0x80},
+		{s: "rgba(255 0 128 / 0.5)", r: 0xFF, b: 0x80},
+		{s: "rgb(100%, 50%, 0%)", r: 0xFF, g: 0x80},
+		{s: "rgb(300, -5, 0)", r: 0xFF},
+		{s: "hsl(0, 100%, 50%)", r: 0xFF},
+		{s: "hsl(120deg 100% 25%)", g: 0x80},
+		{s: "hsla(0.5turn, 100%, 50%, 1)", g: 0xFF, b: 0xFF},
+		{s: "hsl(400grad, 100%, 50%)", r: 0xFF},
+		{s: "hsl(3.14159rad, 100%, 50%)", g: 0xFF, b: 0xFF},
+		{s: "", err: true},
+		{s: "notacolor", err: true},
+		{s: "#12345", err: true},
+		{s: "rgb(1, 2)", err: true},
+		{s: "rgb(1, 2, 3", err: true},
+		{s: "cmyk(1, 2, 3)", err: true},
+		{s: "hsl(x, 100%, 50%)", err: true},
+	}
+	for _, c := range v {
+		r, g, b, err := ParseColor(c.s)
+		if c.err {
+			if err == nil {
+				t.Errorf("ParseColor(%q): expected an error", c.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseColor(%q): unexpected error: %s", c.s, err)
+			continue
+		}
+		if r != c.r || g != c.g || b != c.b {
+			t.Errorf("ParseColor(%q) = %d, %d, %d; want %d, %d, %d", c.s, r, g, b, c.r, c.g, c.b)
+		}
+	}
+}
+func TestXYFromRGBBlack(t *testing.T) {
+	for _, c := range []string{"black", "transparent", "#000"} {
+		x, y, err := xyFromString(*defaultGamut, c)
+		if err != nil {
+			t.Fatalf("xyFromString(%q): unexpected error: %s", c, err)
+		}
+		if math.IsNaN(float64(x)) || math.IsNaN(float64(y)) {
+			t.Fatalf("xyFromString(%q) = %f, %f; want a non-NaN value", c, x, y)
+		}
+		var s LightState
+		if err = s.SetColor(c); err != nil {
+			t.Fatalf("SetColor(%q): unexpected error: %s", c, err)
+		}
+		if _, err = s.marshal(s.mask); err != nil {
+			t.Fatalf("SetColor(%q): marshal failed: %s", c, err)
+		}
+	}
+}
+func TestXYFromTemperature(t *testing.T) {
+	v := []struct {
+		t    uint16
+		x, y float32
+	}{
+		{t: 0, x: 0.2525, y: 0.2523},
+		{t: 40, x: 0.2525, y: 0.2523},
+		{t: 154, x: 0.3136, y: 0.3238},
+		{t: 250, x: 0.3805, y: 0.3767},
+		{t: 370, x: 0.4591, y: 0.4106},
+		{t: 500, x: 0.5269, y: 0.4133},
+		{t: 600, x: 0.5646, y: 0.4029},
+		{t: 1000, x: 0.5646, y: 0.4029},
+	}
+	w := gamut{Red: point{1, 0}, Blue: point{0, 1}, Green: point{0, 0}}
+	for _, c := range v {
+		x, y := xyFromTemperature(w, c.t)
+		if math.Abs(float64(x-c.x)) > 0.001 || math.Abs(float64(y-c.y)) > 0.001 {
+			t.Errorf("xyFromTemperature(%d) = %f, %f; want %f, %f", c.t, x, y, c.x, c.y)
+		}
+	}
+	g := gamut{Red: point{0.35, 0.35}, Blue: point{0.3, 0.4}, Green: point{0.3, 0.3}}
+	for _, c := range v {
+		x, y := xyFromTemperature(g, c.t)
+		if !g.reachable(x, y) {
+			t.Errorf("xyFromTemperature(%d) = %f, %f; want a value inside the gamut", c.t, x, y)
+		}
+	}
+}
+func TestCommon(t *testing.T) {
+	g := []gamut{*defaultGamut, {Red: point{0.7, 0.3}, Blue: point{0.1, 0.8}, Green: point{0.2, 0.05}}}
+	v := []struct {
+		x, y float32
+		k    bool
+	}{
+		{x: 0.3, y: 0.3, k: true},
+		{x: 0.5, y: 0.4, k: true},
+		{x: 0.7, y: 0.3},
+		{x: 0.1, y: 0.8},
+		{x: 0.16, y: 0.04},
+		{x: 0, y: 0},
+		{x: 1, y: 1},
+	}
+	for _, c := range v {
+		x, y := common(g, c.x, c.y)
+		for i := range g {
+			if !g[i].reachable(x, y) {
+				t.Errorf("common(%f, %f) = %f, %f; want a value inside gamut %d", c.x, c.y, x, y, i)
+			}
+		}
+		if c.k && (x != c.x || y != c.y) {
+			t.Errorf("common(%f, %f) = %f, %f; want the value unchanged", c.x, c.y, x, y)
+		}
+	}
+	if x, y := common(nil, 0.9, 0.9); x != 0.9 || y != 0.9 {
+		t.Errorf("common(nil, 0.9, 0.9) = %f, %f; want the value unchanged", x, y)
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	configName uint16 = 1 << iota
+	configChannel
+	configTimezone
+	configDHCP
+	configNetwork
+	configProxy
+	configAutoInstall
+)
+
+// ErrUnsupported is an error returned when a function requires a newer API
+// version than the version reported by the Bridge. Returned errors wrap this
+// error and can be checked with 'errors.Is'.
+var ErrUnsupported = &errval{s: `feature is not supported by the Bridge API version`}
+
+// BridgeConfig represents the configuration of a Hue Bridge and can be used to
+// read and change the Bridge settings.
+//
+// The 'UTC' and 'Local' times and the 'Software' update status are the values
+// reported when the BridgeConfig was retrieved.
+type BridgeConfig struct {
+	UTC, Local sensorTime
+	Software   BridgeUpdate
+	bridge     *Bridge
+	config     bridgeConfig
+	keys       []Key
+
+	ID, MAC, Model      string
+	Version, APIVersion string
+	Datastore           string
+
+	mask   uint16
+	Manual bool
+}
+type bridgeConfig struct {
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"`
+
+	IP           string `json:"ipaddress"`
+	Netmask      string `json:"netmask"`
+	Gateway      string `json:"gateway"`
+	ProxyAddress string `json:"proxyaddress"`
+	ProxyPort    uint16 `json:"proxyport"`
+
+	Channel uint8 `json:"zigbeechannel"`
+	DHCP    bool  `json:"dhcp"`
+}
+
+// Config returns the configuration of the Bridge.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge.
+func (b *Bridge) Config() (*BridgeConfig, error) {
+	return b.ConfigContext(b.ctx)
+}
+
+// ConfigContext returns the configuration of the Bridge. The configuration is
+// cached after the first call, use 'Update' on the BridgeConfig to refresh it.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge. This function allows for usage of an additional Context to be used
+// instead of the Bridge base context.
+func (b *Bridge) ConfigContext(x context.Context) (*BridgeConfig, error) {
+	b.lock.RLock()
+	c := b.config
+	if b.lock.RUnlock(); c != nil {
+		return c, nil
+	}
+	r, err := b.request(x, http.MethodGet, "/config", nil)
+	if err != nil {
+		return nil, err
+	}
+	c = &BridgeConfig{bridge: b}
+	if err = c.unmarshal(r); err != nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	b.config = c
+	b.lock.Unlock()
+	return c, nil
+}
+
+// Supports returns true if the Bridge API version is equal to or newer than the
+// specified version, such as "1.22.0".
+//
+// This function returns false if the configuration of the Bridge cannot be
+// retrieved.
+func (b *Bridge) Supports(v string) bool {
+	return b.require(b.ctx, v, "") == nil
+}
+func (b *Bridge) require(x context.Context, v, n string) error {
+	c, err := b.ConfigContext(x)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(v) {
+		return &errval{s: n + ` requires API version ` + v + `, Bridge is ` + c.APIVersion, e: ErrUnsupported}
+	}
+	return nil
+}
+
+// Supports returns true if the API version of this BridgeConfig is equal to or
+// newer than the specified version, such as "1.22.0".
+func (c *BridgeConfig) Supports(v string) bool {
+	return compareVersion(c.APIVersion, v) >= 0
+}
+func compareVersion(a, b string) int {
+	var (
+		x = strings.Split(a, ".")
+		y = strings.Split(b, ".")
+	)
+	for i := 0; i < len(x) || i < len(y); i++ {
+		var n, m int
+		if i < len(x) {
+			n, _ = strconv.Atoi(x[i])
+		}
+		if i < len(y) {
+			m, _ = strconv.Atoi(y[i])
+		}
+		switch {
+		case n < m:
+			return -1
+		case n > m:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Keys returns the access keys (whitelist) of the Bridge, sorted by the last used
+// time, with the most recently used key first.
+func (c *BridgeConfig) Keys() []Key {
+	if c.bridge != nil {
+		c.bridge.lock.RLock()
+		defer c.bridge.lock.RUnlock()
+	}
+	r := make([]Key, len(c.keys))
+	copy(r, c.keys)
+	return r
+}
+
+// Name returns the Bridge's display name.
+func (c *BridgeConfig) Name() string {
+	return c.config.Name
+}
+
+// DHCP returns true if the Bridge uses DHCP to get its network settings.
+func (c *BridgeConfig) DHCP() bool {
+	return c.config.DHCP
+}
+
+// Channel returns the ZigBee channel used by the Bridge.
+func (c *BridgeConfig) Channel() uint8 {
+	return c.config.Channel
+}
+
+// Timezone returns the timezone of the Bridge, such as "Europe/Amsterdam".
+func (c *BridgeConfig) Timezone() string {
+	return c.config.Timezone
+}
+
+// Network returns the IP address, netmask and gateway of the Bridge.
+func (c *BridgeConfig) Network() (ip, netmask, gateway string) {
+	return c.config.IP, c.config.Netmask, c.config.Gateway
+}
+
+// Proxy returns the HTTP proxy address and port used by the Bridge. The address
+// is "none" if no proxy is used.
+func (c *BridgeConfig) Proxy() (string, uint16) {
+	return c.config.ProxyAddress, c.config.ProxyPort
+}
+
+// Update will attempt to sync any changes that have been set while "Manual" is
+// set to "true".
+//
+// This function will return any errors that occur during updating.
+func (c *BridgeConfig) Update() error {
+	return c.UpdateContext(c.bridge.ctx)
+}
+
+// SetName will change the Bridge's display name. The name must be between 4 and
+// 16 characters.
+//
+// This function returns any errors during setting the display name.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (c *BridgeConfig) SetName(n string) error {
+	if len(n) < 4 || len(n) > 16 {
+		return &errval{s: `invalid Bridge name "` + n + `"`}
+	}
+	c.config.Name = n
+	if c.mask |= configName; c.Manual {
+		return nil
+	}
+	return c.UpdateContext(c.bridge.ctx)
+}
+
+// SetDHCP will change if the Bridge uses DHCP to get its network settings.
+//
+// This function returns any errors during setting the value.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (c *BridgeConfig) SetDHCP(e bool) error {
+	c.config.DHCP = e
+	if c.mask |= configDHCP; c.Manual {
+		return nil
+	}
+	return c.UpdateContext(c.bridge.ctx)
+}
+
+// SetChannel will change the ZigBee channel used by the Bridge. The channel must
+// be 11, 15, 20 or 25.
+//
+// This function returns any errors during setting the channel.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (c *BridgeConfig) SetChannel(n uint8) error {
+	if n != 11 && n != 15 && n != 20 && n != 25 {
+		return &errval{s: `invalid ZigBee channel "` + strconv.Itoa(int(n)) + `"`}
+	}
+	c.config.Channel = n
+	if c.mask |= configChannel; c.Manual {
+		return nil
+	}
+	return c.UpdateContext(c.bridge.ctx)
+}
+
+// SetTimezone will change the timezone of the Bridge. The timezone must be a name
+// from the IANA timezone database, such as "Europe/Amsterdam".
+//
+// This function returns any errors during setting the timezone.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (c *BridgeConfig) SetTimezone(s string) error {
+	c.config.Timezone = s
+	if c.mask |= configTimezone; c.Manual {
+		return nil
+	}
+	return c.UpdateContext(c.bridge.ctx)
+}
+
+// SetNetwork will change the static IPv4 address, netmask and gateway of the
+// Bridge. These values are only used when DHCP is disabled.
+//
+// This function returns any errors during setting the values.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (c *BridgeConfig) SetNetwork(ip, netmask, gateway string) error {
+	for _, v := range [...]string{ip, netmask, gateway} {
+		if a := net.ParseIP(v); a == nil || a.To4() == nil {
+			return &errval{s: `invalid IPv4 address "` + v + `"`}
+		}
+	}
+	c.config.IP, c.config.Netmask, c.config.Gateway = ip, netmask, gateway
+	if c.mask |= configNetwork; c.Manual {
+		return nil
+	}
+	return c.UpdateContext(c.bridge.ctx)
+}
+
+// SetProxy will change the HTTP proxy used by the Bridge. Use an empty address
+// or "none" to disable the proxy.
+//
+// This function returns any errors during setting the proxy.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (c *BridgeConfig) SetProxy(a string, p uint16) error {
+	if len(a) == 0 || a == "none" {
+		a, p = "none", 0
+	}
+	c.config.ProxyAddress, c.config.ProxyPort = a, p
+	if c.mask |= configProxy; c.Manual {
+		return nil
+	}
+	return c.UpdateContext(c.bridge.ctx)
+}
+
+// UpdateContext will attempt to sync any changes that have been set while
+// "Manual" is set to "true". If no changes are waiting, the BridgeConfig will be
+// refreshed from the Bridge.
+//
+// This function will return any errors that occur during updating.
+//
+// This function allows a Context to be specified to be used instead of the
+// Bridge's base Context.
+func (c *BridgeConfig) UpdateContext(x context.Context) error {
+	if c.mask == 0 {
+		r, err := c.bridge.request(x, http.MethodGet, "/config", nil)
+		if err != nil {
+			return err
+		}
+		return c.unmarshal(r)
+	}
+	b, err := c.marshal()
+	if err != nil {
+		return err
+	}
+	if _, err = c.bridge.request(x, http.MethodPut, "/config", b); err != nil {
+		return err
+	}
+	c.mask = 0
+	return nil
+}
+func (c bridgeConfig) values(m uint16) map[string]interface{} {
+	i := make(map[string]interface{})
+	if m&configName != 0 {
+		i["name"] = c.Name
+	}
+	if m&configChannel != 0 {
+		i["zigbeechannel"] = c.Channel
+	}
+	if m&configTimezone != 0 {
+		i["timezone"] = c.Timezone
+	}
+	if m&configDHCP != 0 {
+		i["dhcp"] = c.DHCP
+	}
+	if m&configNetwork != 0 {
+		i["ipaddress"], i["netmask"], i["gateway"] = c.IP, c.Netmask, c.Gateway
+	}
+	if m&configProxy != 0 {
+		i["proxyaddress"], i["proxyport"] = c.ProxyAddress, c.ProxyPort
+	}
+	return i
+}
+func (c *BridgeConfig) marshal() ([]byte, error) {
+	i := c.config.values(c.mask)
+	if c.mask&configAutoInstall != 0 {
+		i["swupdate2"] = map[string]interface{}{
+			"autoinstall": map[string]interface{}{"on": c.Software.AutoInstall, "updatetime": c.Software.autoTime()},
+		}
+	}
+	return json.Marshal(i)
+}
+func (c *BridgeConfig) unmarshal(d []byte) error {
+	var v struct {
+		UTC       sensorTime   `json:"UTC"`
+		Local     sensorTime   `json:"localtime"`
+		ID        string       `json:"bridgeid"`
+		MAC       string       `json:"mac"`
+		Model     string       `json:"modelid"`
+		Version   string       `json:"swversion"`
+		API       string       `json:"apiversion"`
+		Datastore string       `json:"datastoreversion"`
+		Software  BridgeUpdate `json:"swupdate2"`
+		Whitelist map[string]struct {
+			Created  sensorTime `json:"create date"`
+			LastUsed sensorTime `json:"last use date"`
+			Name     string     `json:"name"`
+		} `json:"whitelist"`
+		bridgeConfig
+	}
+	if err := json.Unmarshal(d, &v); err != nil {
+		return err
+	}
+	if len(v.API) == 0 {
+		return &errval{s: `missing "apiversion" parameter value`}
+	}
+	w := make([]Key, 0, len(v.Whitelist))
+	for k, e := range v.Whitelist {
+		w = append(w, Key{Created: e.Created.Time, LastUsed: e.LastUsed.Time, Key: k, Name: e.Name})
+	}
+	sort.Slice(w, func(i, j int) bool { return w[i].LastUsed.After(w[j].LastUsed) })
+	if c.bridge != nil {
+		c.bridge.lock.Lock()
+		c.keys = w
+		c.bridge.lock.Unlock()
+	} else {
+		c.keys = w
+	}
+	c.UTC, c.Local, c.config, c.Software = v.UTC, v.Local, v.bridgeConfig, v.Software
+	c.ID, c.MAC, c.Model, c.Version, c.APIVersion, c.Datastore = v.ID, v.MAC, v.Model, v.Version, v.API, v.Datastore
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import "testing"
+
+func TestCompareVersion(t *testing.T) {
+	v := []struct {
+		a, b string
+		r    int
+	}{
+		{a: "1.50.0", b: "1.50.0", r: 0},
+		{a: "1.50.0", b: "1.50", r: 0},
+		{a: "1.50", b: "1.50.1", r: -1},
+		{a: "1.9.0", b: "1.10.0", r: -1},
+		{a: "1.10.0", b: "1.9.0", r: 1},
+		{a: "2.0.0", b: "1.99.99", r: 1},
+		{a: "1.22.0", b: "1.22.1", r: -1},
+		{a: "", b: "", r: 0},
+		{a: "", b: "1.0.0", r: -1},
+		{a: "1.x.0", b: "1.0.0", r: 0},
+	}
+	for _, c := range v {
+		if r := compareVersion(c.a, c.b); r != c.r {
+			t.Errorf("compareVersion(%q, %q) = %d, want %d", c.a, c.b, r, c.r)
+		}
+		c.a, c.b = c.b, c.a
+		if r := compareVersion(c.a, c.b); r != -c.r {
+			t.Errorf("compareVersion(%q, %q) = %d, want %d", c.a, c.b, r, -c.r)
+		}
+	}
+	if c := (BridgeConfig{APIVersion: "1.48.0"}); !c.Supports("1.22.0") || c.Supports("1.50.0") {
+		t.Errorf("Supports returned the wrong result for API version %q", c.APIVersion)
+	}
+}
@@ -33,6 +33,9 @@ const (
 	maskName
 	maskStartup
 	maskLed
+	maskLocation
+	maskArchetype
+	maskFunction
 	maskAll = uint16(65535)
 )
 
@@ -40,16 +43,26 @@ const (
 // a Lights or something that can be toggled, such as an outlet.
 type Control struct {
 	startup controlStartup
+	config  controlConfig
 	bridge  *Bridge
 
 	ID, Model, Product string
 	name               string
 
-	UUID, Make string
-	state      controlState
-	mask       uint16
+	UUID, Make, Version string
+	Software            SoftwareUpdate
+	state               controlState
+	known               controlState
+	mask                uint16
+	kind                DeviceKind
 
 	Manual bool
+	// Diff determines how changes to the state of the Control are compared to
+	// the current state before being sent, so values that would not change the
+	// Control are not sent. See the DiffMode constants for more info.
+	//
+	// Defaults to DiffNone, which sends every value that is set.
+	Diff DiffMode
 }
 type decoder struct {
 	l *Light
@@ -59,6 +72,11 @@ type decoder struct {
 // StartupMode is a representation of the power on mode of the Control.
 type StartupMode uint8
 
+type controlConfig struct {
+	Archetype string         `json:"archetype,omitempty"`
+	Function  LightFunction  `json:"function,omitempty"`
+	Direction LightDirection `json:"direction,omitempty"`
+}
 type controlStartup struct {
 	Settings *controlState `json:"customsettings,omitempty"`
 	Mode     StartupMode   `json:"mode"`
@@ -135,6 +153,19 @@ func (c *Control) SetOn(s bool) error {
 	return c.UpdateContext(c.bridge.ctx)
 }
 
+// Apply will send the values set in the LightState to the Control. Only the
+// values that have been set on the LightState will be sent.
+//
+// Controls only support the On and Alert values, any other values set on the
+// LightState are ignored, so the same LightState can be applied to Lights and
+// Controls.
+//
+// This function does not use the 'Manual' attribute and will not send any
+// changes that are waiting for an 'Update*' function call.
+func (c *Control) Apply(x context.Context, s LightState) error {
+	return c.apply(x, s.controlState, s.mask&(maskOn|maskAlert))
+}
+
 // Startup returns the power on method of the Control.
 func (c *Control) Startup() StartupMode {
 	return c.startup.Mode
@@ -177,6 +208,31 @@ func (c *Control) marshal() ([]byte, error) {
 	}
 	return json.Marshal(m)
 }
+func (c *Control) metadata(x context.Context) error {
+	// The archetype and function are read-only in the v1 API, so they are
+	// changed through the CLIP API v2 light that matches this Control.
+	var (
+		k      = c.bridge.Clip()
+		l, err = k.Lights(x)
+	)
+	if err != nil {
+		return err
+	}
+	for i := range l {
+		if l[i].IDv1 != "/lights/"+c.ID {
+			continue
+		}
+		var m ClipMetadata
+		if c.mask&maskArchetype != 0 {
+			m.Archetype = c.config.Archetype
+		}
+		if c.mask&maskFunction != 0 {
+			m.Function = c.config.Function.String()
+		}
+		return k.UpdateLight(x, l[i].ID, ClipLightUpdate{Metadata: &m})
+	}
+	return &errval{s: `could not find the CLIP API v2 light for "/lights/` + c.ID + `"`}
+}
 
 // SetPowerOn will change the Control's power on state.
 //
@@ -237,6 +293,15 @@ func (c *Control) UpdateContext(x context.Context) error {
 		}
 		return c.unmarshal(m)
 	}
+	if c.mask&(maskArchetype|maskFunction) != 0 {
+		err := c.metadata(x)
+		if c.mask = c.mask &^ (maskArchetype | maskFunction); err != nil {
+			return err
+		}
+		if c.mask == 0 {
+			return nil
+		}
+	}
 	if c.mask >= maskName {
 		b, err := c.marshal()
 		if c.mask = c.mask &^ maskStartup; err != nil {
@@ -249,16 +314,57 @@ func (c *Control) UpdateContext(x context.Context) error {
 			return nil
 		}
 	}
-	b, err := c.state.marshal(c.mask)
+	m, err := c.diff(x, c.state, c.mask)
+	if err != nil {
+		return err
+	}
+	if m == 0 {
+		c.mask = 0
+		return nil
+	}
+	b, err := c.state.marshal(m)
 	if err != nil {
 		return err
 	}
 	if _, err = c.bridge.request(x, http.MethodPut, "/lights/"+c.ID+"/state", b); err != nil {
 		return err
 	}
+	c.known.merge(c.state, m)
 	c.mask = 0
 	return err
 }
+func (c *Control) diff(x context.Context, s controlState, m uint16) (uint16, error) {
+	switch c.Diff {
+	case DiffNone:
+		return m, nil
+	case DiffFetch:
+		v, err := c.fetch(x)
+		if err != nil {
+			return 0, err
+		}
+		c.known = v
+	}
+	return s.diff(c.known, m), nil
+}
+func (c *Control) apply(x context.Context, s controlState, m uint16) error {
+	if m == 0 {
+		return nil
+	}
+	m, err := c.diff(x, s, m)
+	if err != nil || m == 0 {
+		return err
+	}
+	b, err := s.marshal(m)
+	if err != nil {
+		return err
+	}
+	if _, err = c.bridge.request(x, http.MethodPut, "/lights/"+c.ID+"/state", b); err != nil {
+		return err
+	}
+	c.state.merge(s, m)
+	c.known.merge(s, m)
+	return nil
+}
 func (c *Control) unmarshal(d map[string]json.RawMessage) error {
 	v, ok := d["name"]
 	if !ok {
@@ -291,17 +397,32 @@ func (c *Control) unmarshal(d map[string]json.RawMessage) error {
 	if err := json.Unmarshal(v, &c.Product); err != nil {
 		return err
 	}
+	if v, ok = d["swversion"]; ok {
+		if err := json.Unmarshal(v, &c.Version); err != nil {
+			return err
+		}
+	}
+	if v, ok = d["swupdate"]; ok {
+		if err := json.Unmarshal(v, &c.Software); err != nil {
+			return err
+		}
+	}
 	if v, ok = d["state"]; !ok {
 		return &errval{s: `missing "state" parameter value`}
 	}
 	if err := json.Unmarshal(v, &c.state); err != nil {
 		return err
 	}
+	c.known = c.state
 	if v, ok = d["config"]; ok {
 		var m map[string]json.RawMessage
 		if err := json.Unmarshal(v, &m); err != nil {
 			return err
 		}
+		c.config = controlConfig{}
+		if err := json.Unmarshal(v, &c.config); err != nil {
+			return err
+		}
 		if v, ok = m["startup"]; ok {
 			if err := json.Unmarshal(v, &c.startup); err != nil {
 				return err
@@ -323,31 +444,31 @@ func (j *decoder) unmarshal(i string, b *Bridge, d []byte) error {
 		return err
 	}
 	j.c.bridge, j.c.ID = b, i
-	var (
-		c     map[string]json.RawMessage
-		v, ok = m["capabilities"]
-	)
-	if !ok {
-		return nil
-	}
-	if err = json.Unmarshal(v, &c); err != nil {
-		return err
-	}
-	if v, ok = c["control"]; !ok {
-		return nil
-	}
-	if err = json.Unmarshal(v, &c); err != nil {
-		return err
-	}
-	if len(v) == 0 || len(c) == 0 {
-		return nil
+	var c map[string]json.RawMessage
+	if v, ok := m["capabilities"]; ok {
+		var a map[string]json.RawMessage
+		if err = json.Unmarshal(v, &a); err != nil {
+			return err
+		}
+		if v, ok = a["control"]; ok && len(v) > 0 {
+			if err = json.Unmarshal(v, &c); err != nil {
+				return err
+			}
+		}
 	}
-	_, ct := c["ct"]
-	if _, ok = c["maxlumen"]; !ok && !ct {
+	// Only Controls that support brightness are Lights. The kind is used instead
+	// of the capabilities, as some third-party Lights do not report them.
+	if j.c.kind = kindOf(j.c.Make, c, m["state"]); j.c.kind < KindDimmable {
 		return nil
 	}
 	j.l = &Light{Control: *j.c}
-	if v, ok = c["colorgamut"]; ok {
+	if v, ok := c["ct"]; ok {
+		j.l.ct = new(ctRange)
+		if err := json.Unmarshal(v, &j.l.ct); err != nil {
+			return err
+		}
+	}
+	if v, ok := c["colorgamut"]; ok {
 		j.l.gamut = new(gamut)
 		if err := json.Unmarshal(v, &j.l.gamut); err != nil {
 			return err
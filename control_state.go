@@ -44,14 +44,46 @@ const (
 	// color mode when the device resumes from a power loss.
 	StartupDefault = StartupMode(0)
 	startupCustom  = StartupMode(3)
+
+	// DiffNone is a DiffMode that will send every value that was set, even if
+	// the Light or Control is already in that state. This is the default.
+	DiffNone = DiffMode(0)
+	// DiffCached is a DiffMode that will compare the values that were set to the
+	// last known state of the Light or Control and only send the changed values.
+	DiffCached = DiffMode(1)
+	// DiffFetch is a DiffMode that will fetch the current state of the Light or
+	// Control from the Bridge and only send the changed values. This requires
+	// an extra request to the Bridge, but is accurate even when the state is
+	// changed by something else.
+	DiffFetch = DiffMode(2)
+)
+
+const (
+	// diffXY is the amount a XY value may differ and still be considered equal,
+	// as the Bridge rounds the values it reports.
+	diffXY = 0.0015
+	// diffBrightness is the amount a brightness value may differ and still be
+	// considered equal, as some Lights round the values they report.
+	diffBrightness = 1
+)
+
+const (
+	colorNone color = iota
+	colorXY
+	colorHS
+	colorCT
 )
 
-type color bool
+type color uint8
 
 // Effect represents the type of light Effect that can be applied to a Hue Control
 // object.
 type Effect bool
 
+// DiffMode represents how a Light or Control will determine which values need
+// to be sent to the Bridge when changing it's state.
+type DiffMode uint8
+
 // Alert represents the type of Alert effect that can be applied to a Hue Control
 // object.
 type Alert uint8
@@ -108,11 +140,27 @@ func (a *Alert) UnmarshalJSON(d []byte) error {
 	}
 	return nil
 }
+func (c color) MarshalJSON() ([]byte, error) {
+	switch c {
+	case colorHS:
+		return []byte(`"hs"`), nil
+	case colorCT:
+		return []byte(`"ct"`), nil
+	}
+	return []byte(`"xy"`), nil
+}
 func (c *color) UnmarshalJSON(d []byte) error {
 	if len(d) < 4 || d[0] != '"' {
 		return &errval{s: `invalid Color value`}
 	}
-	*c = true
+	switch d[1] {
+	case 'h', 'H':
+		*c = colorHS
+	case 'c', 'C':
+		*c = colorCT
+	default:
+		*c = colorXY
+	}
 	return nil
 }
 
@@ -131,6 +179,74 @@ func (e *Effect) UnmarshalJSON(d []byte) error {
 	}
 	return nil
 }
+func (s *controlState) merge(o controlState, m uint16) {
+	if m&maskOn != 0 {
+		s.On = o.On
+	}
+	if m&maskAlert != 0 {
+		s.Alert = o.Alert
+	}
+	if m&maskEffect != 0 {
+		s.Effect = o.Effect
+	}
+	if m&maskBrightness != 0 {
+		s.Brightness = o.Brightness
+	}
+	// The Bridge will use the color mode by priority, XY is first, followed by
+	// temperature, then hue and saturation.
+	if m&(maskHue|maskSaturation) != 0 {
+		if m&maskHue != 0 {
+			s.Hue = o.Hue
+		}
+		if m&maskSaturation != 0 {
+			s.Saturation = o.Saturation
+		}
+		if s.Color != colorNone {
+			s.Color = colorHS
+		}
+	}
+	if m&maskTemperature != 0 {
+		if s.Temperature = o.Temperature; s.Color != colorNone {
+			s.Color = colorCT
+		}
+	}
+	if m&maskXY != 0 {
+		if s.XY = o.XY; s.Color != colorNone {
+			s.Color = colorXY
+		}
+	}
+}
+func (s controlState) diff(o controlState, m uint16) uint16 {
+	// Alerts are actions and not a state, so they are always sent.
+	if m&maskOn != 0 && s.On == o.On {
+		m &^= maskOn
+	}
+	if m&maskEffect != 0 && s.Effect == o.Effect {
+		m &^= maskEffect
+	}
+	if m&maskBrightness != 0 && delta(float32(s.Brightness), float32(o.Brightness)) <= diffBrightness {
+		m &^= maskBrightness
+	}
+	if m&maskXY != 0 && o.Color == colorXY && delta(s.XY[0], o.XY[0]) <= diffXY && delta(s.XY[1], o.XY[1]) <= diffXY {
+		m &^= maskXY
+	}
+	if m&maskTemperature != 0 && o.Color == colorCT && s.Temperature == o.Temperature {
+		m &^= maskTemperature
+	}
+	if m&maskHue != 0 && o.Color == colorHS && s.Hue == o.Hue {
+		m &^= maskHue
+	}
+	if m&maskSaturation != 0 && o.Color == colorHS && s.Saturation == o.Saturation {
+		m &^= maskSaturation
+	}
+	return m
+}
+func delta(a, b float32) float32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
 func (s controlState) marshal(m uint16) ([]byte, error) {
 	i := make(map[string]interface{})
 	if m&maskOn != 0 {
@@ -0,0 +1,188 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// References contains the IDs of the Rules, Scenes and ResourceLinks that
+// reference a resource. This is returned when deleting a resource, as the Bridge
+// does not always remove references to deleted resources.
+type References struct {
+	Rules         []string
+	Scenes        []string
+	ResourceLinks []string
+}
+
+// Empty returns true if there are no References.
+func (r References) Empty() bool {
+	return len(r.Rules) == 0 && len(r.Scenes) == 0 && len(r.ResourceLinks) == 0
+}
+
+// Delete will remove this Control (or Light) from the Bridge. The Control is also
+// removed from the Bridge and from the members of every Group.
+//
+// The returned References contain the Rules, Scenes and ResourceLinks that still
+// reference this Control after it was deleted. If the Control was deleted, but
+// the References could not be checked, the error is returned with the Control
+// still removed.
+func (c *Control) Delete(x context.Context) (References, error) {
+	if _, err := c.bridge.request(x, http.MethodDelete, "/lights/"+c.ID, nil); err != nil {
+		return References{}, err
+	}
+	b := c.bridge
+	b.lock.Lock()
+	delete(b.lights, c.ID)
+	delete(b.controls, c.ID)
+	for _, g := range b.groups {
+		g.remove(c.ID, false)
+	}
+	if b.all != nil {
+		b.all.remove(c.ID, false)
+	}
+	b.lock.Unlock()
+	return b.references(x, "/lights/"+c.ID, true)
+}
+
+// Delete will remove this Sensor from the Bridge. The Sensor is also removed from
+// the Bridge and from the members of every Group.
+//
+// The returned References contain the Rules, Scenes and ResourceLinks that still
+// reference this Sensor after it was deleted. If the Sensor was deleted, but the
+// References could not be checked, the error is returned with the Sensor still
+// removed.
+func (s *Sensor) Delete(x context.Context) (References, error) {
+	if _, err := s.bridge.request(x, http.MethodDelete, "/sensors/"+s.ID, nil); err != nil {
+		return References{}, err
+	}
+	b := s.bridge
+	b.lock.Lock()
+	delete(b.sensors, s.ID)
+	for _, g := range b.groups {
+		g.remove(s.ID, true)
+	}
+	if b.all != nil {
+		b.all.remove(s.ID, true)
+	}
+	b.lock.Unlock()
+	return b.references(x, "/sensors/"+s.ID, false)
+}
+func (g *Group) remove(i string, s bool) {
+	if s {
+		for k := range g.Sensors {
+			if g.Sensors[k].ID == i {
+				g.Sensors = append(g.Sensors[:k], g.Sensors[k+1:]...)
+				break
+			}
+		}
+		return
+	}
+	for k := range g.Lights {
+		if g.Lights[k].ID == i {
+			g.Lights = append(g.Lights[:k], g.Lights[k+1:]...)
+			break
+		}
+	}
+	for k := range g.Controls {
+		if g.Controls[k].ID == i {
+			g.Controls = append(g.Controls[:k], g.Controls[k+1:]...)
+			break
+		}
+	}
+	delete(g.locations, i)
+}
+func (b *Bridge) references(x context.Context, p string, l bool) (References, error) {
+	var (
+		o References
+		m = func(a string) bool { return a == p || strings.HasPrefix(a, p+"/") }
+	)
+	r, err := b.request(x, http.MethodGet, "/rules", nil)
+	if err != nil {
+		return o, &errval{s: `could not check Rule references`, e: err}
+	}
+	var u map[string]struct {
+		Conditions []struct {
+			Address string `json:"address"`
+		} `json:"conditions"`
+		Actions []struct {
+			Address string `json:"address"`
+		} `json:"actions"`
+	}
+	if err = json.Unmarshal(r, &u); err != nil {
+		return o, &errval{s: `could not unmarshal Rule JSON`, e: err}
+	}
+rules:
+	for k, v := range u {
+		for _, c := range v.Conditions {
+			if m(c.Address) {
+				o.Rules = append(o.Rules, k)
+				continue rules
+			}
+		}
+		for _, a := range v.Actions {
+			if m(a.Address) {
+				o.Rules = append(o.Rules, k)
+				continue rules
+			}
+		}
+	}
+	if l {
+		if r, err = b.request(x, http.MethodGet, "/scenes", nil); err != nil {
+			return o, &errval{s: `could not check Scene references`, e: err}
+		}
+		var s map[string]struct {
+			Lights []string `json:"lights"`
+		}
+		if err = json.Unmarshal(r, &s); err != nil {
+			return o, &errval{s: `could not unmarshal Scene JSON`, e: err}
+		}
+		i := p[strings.LastIndexByte(p, '/')+1:]
+		for k, v := range s {
+			for _, e := range v.Lights {
+				if e == i {
+					o.Scenes = append(o.Scenes, k)
+					break
+				}
+			}
+		}
+	}
+	if r, err = b.request(x, http.MethodGet, "/resourcelinks", nil); err != nil {
+		return o, &errval{s: `could not check ResourceLink references`, e: err}
+	}
+	var n map[string]struct {
+		Links []string `json:"links"`
+	}
+	if err = json.Unmarshal(r, &n); err != nil {
+		return o, &errval{s: `could not unmarshal ResourceLink JSON`, e: err}
+	}
+	for k, v := range n {
+		for _, e := range v.Links {
+			if m(e) {
+				o.ResourceLinks = append(o.ResourceLinks, k)
+				break
+			}
+		}
+	}
+	sort.Strings(o.Rules)
+	sort.Strings(o.Scenes)
+	sort.Strings(o.ResourceLinks)
+	return o, nil
+}
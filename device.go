@@ -0,0 +1,212 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Device represents a single physical device that may provide multiple Lights,
+// Controls or Sensors. For example, a motion sensor provides a presence, light
+// level and temperature Sensor, and a dual outlet plug provides two Controls.
+//
+// Resources are grouped by the MAC address portion of their UUID. Resources
+// without a UUID, such as CLIP Sensors, are not part of any Device.
+//
+// The 'Model', 'Product' and 'Version' values are taken from the first child
+// resource.
+type Device struct {
+	Lights   []*Light
+	Controls []*Control
+	Sensors  []*Sensor
+
+	MAC, Model    string
+	Product, Make string
+	Version       string
+}
+
+// Devices returns all the physical devices connected to the Bridge, sorted by
+// their MAC address.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge.
+func (b *Bridge) Devices() ([]*Device, error) {
+	return b.DevicesContext(b.ctx)
+}
+
+// DevicesContext returns all the physical devices connected to the Bridge,
+// sorted by their MAC address.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge. This function allows for usage of an additional Context to be used
+// instead of the Bridge base context.
+func (b *Bridge) DevicesContext(x context.Context) ([]*Device, error) {
+	if _, err := b.LightsContext(x); err != nil {
+		return nil, err
+	}
+	if _, err := b.SensorsContext(x); err != nil {
+		return nil, err
+	}
+	m := make(map[string]*Device)
+	d := func(u string) *Device {
+		i := deviceMAC(u)
+		if len(i) == 0 {
+			return nil
+		}
+		v, ok := m[i]
+		if !ok {
+			v = &Device{MAC: i}
+			m[i] = v
+		}
+		return v
+	}
+	b.lock.RLock()
+	for _, l := range b.lights {
+		if v := d(l.UUID); v != nil {
+			v.Lights = append(v.Lights, l)
+		}
+	}
+	for _, c := range b.controls {
+		if v := d(c.UUID); v != nil {
+			v.Controls = append(v.Controls, c)
+		}
+	}
+	for _, s := range b.sensors {
+		if v := d(s.UUID); v != nil {
+			v.Sensors = append(v.Sensors, s)
+		}
+	}
+	b.lock.RUnlock()
+	o := make([]*Device, 0, len(m))
+	for _, v := range m {
+		sort.Slice(v.Lights, func(i, j int) bool { return lessID(v.Lights[i].ID, v.Lights[j].ID) })
+		sort.Slice(v.Controls, func(i, j int) bool { return lessID(v.Controls[i].ID, v.Controls[j].ID) })
+		sort.Slice(v.Sensors, func(i, j int) bool { return lessID(v.Sensors[i].ID, v.Sensors[j].ID) })
+		switch {
+		case len(v.Lights) > 0:
+			v.Model, v.Product, v.Make, v.Version = v.Lights[0].Model, v.Lights[0].Product, v.Lights[0].Make, v.Lights[0].Version
+		case len(v.Controls) > 0:
+			v.Model, v.Product, v.Make, v.Version = v.Controls[0].Model, v.Controls[0].Product, v.Controls[0].Make, v.Controls[0].Version
+		default:
+			v.Model, v.Product, v.Make, v.Version = v.Sensors[0].Model, v.Sensors[0].Product, v.Sensors[0].Make, v.Sensors[0].Version
+		}
+		o = append(o, v)
+	}
+	sort.Slice(o, func(i, j int) bool { return o[i].MAC < o[j].MAC })
+	return o, nil
+}
+
+// Device returns the physical device that provides the resource with the
+// specified UUID or MAC address.
+//
+// This function returns nil if there is no matching Device.
+func (b *Bridge) Device(s string) *Device {
+	i := deviceMAC(s)
+	if len(i) == 0 {
+		return nil
+	}
+	d, err := b.DevicesContext(b.ctx)
+	if err != nil {
+		return nil
+	}
+	for _, v := range d {
+		if v.MAC == i {
+			return v
+		}
+	}
+	return nil
+}
+func deviceMAC(u string) string {
+	if i := strings.IndexByte(u, '-'); i > 0 {
+		u = u[:i]
+	}
+	// ZigBee MAC addresses are 8 bytes, "00:17:88:01:00:bd:c7:b9".
+	if len(u) != 23 || strings.Count(u, ":") != 7 {
+		return ""
+	}
+	return strings.ToLower(u)
+}
+func lessID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// Reachable returns true if every Light, Control and Sensor of this Device is
+// reachable by the Bridge.
+func (d *Device) Reachable() bool {
+	for _, v := range d.Lights {
+		if !v.Reachable() {
+			return false
+		}
+	}
+	for _, v := range d.Controls {
+		if !v.Reachable() {
+			return false
+		}
+	}
+	for _, v := range d.Sensors {
+		if !v.Reachable() {
+			return false
+		}
+	}
+	return true
+}
+
+// Battery returns the lowest battery level reported by the Sensors of this
+// Device. The boolean is false if no battery level is reported.
+func (d *Device) Battery() (uint8, bool) {
+	var (
+		r  uint8 = 255
+		ok bool
+	)
+	for _, v := range d.Sensors {
+		if !v.HasBattery() {
+			continue
+		}
+		if b := v.Battery(); !ok || b < r {
+			r, ok = b, true
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return r, true
+}
+
+// Updating returns true if a software update is being transferred to or
+// installed on any resource of this Device.
+func (d *Device) Updating() bool {
+	for _, v := range d.Lights {
+		if v.Software.State.Active() {
+			return true
+		}
+	}
+	for _, v := range d.Controls {
+		if v.Software.State.Active() {
+			return true
+		}
+	}
+	for _, v := range d.Sensors {
+		if v.Software.State.Active() {
+			return true
+		}
+	}
+	return false
+}
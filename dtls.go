@@ -0,0 +1,360 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	dtlsChangeCipher = 20
+	dtlsAlert        = 21
+	dtlsHandshake    = 22
+	dtlsData         = 23
+)
+const (
+	dtlsClientHello              = 1
+	dtlsServerHello              = 2
+	dtlsHelloVerify              = 3
+	dtlsServerKeyExchange        = 12
+	dtlsServerHelloDone          = 14
+	dtlsClientKeyExchange        = 16
+	dtlsFinished                 = 20
+	dtlsSuite             uint16 = 0x00A8 // TLS_PSK_WITH_AES_128_GCM_SHA256
+	dtlsRetries                  = 6
+)
+
+var errHandshake = errors.New("invalid handshake message")
+
+// dtlsConn is a minimal DTLS 1.2 client that only supports the
+// TLS_PSK_WITH_AES_128_GCM_SHA256 cipher suite, which is the only suite that is
+// accepted by the Bridge for Entertainment streaming.
+//
+// Only writing application data is supported once the handshake is complete.
+type dtlsConn struct {
+	conn net.Conn
+	w, r cipher.AEAD
+
+	seq [2]uint64
+	wIV [4]byte
+	rIV [4]byte
+}
+type dtlsState struct {
+	*dtlsConn
+	hash   hash.Hash
+	pend   cipher.AEAD
+	master []byte
+	random [64]byte
+	msg    uint16
+	next   uint16
+}
+
+func put24(b []byte, v int) {
+	b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v)
+}
+func get24(b []byte) int {
+	return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+}
+func (c *dtlsConn) Close() error {
+	if c.w != nil {
+		c.conn.Write(c.record(1, dtlsAlert, []byte{1, 0}))
+	}
+	return c.conn.Close()
+}
+func (c *dtlsConn) Write(b []byte) (int, error) {
+	if _, err := c.conn.Write(c.record(1, dtlsData, b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+func prf(s []byte, l string, d []byte, n int) []byte {
+	var (
+		h    = hmac.New(sha256.New, s)
+		seed = append([]byte(l), d...)
+		a    = seed
+		r    = make([]byte, 0, n+sha256.Size)
+	)
+	for len(r) < n {
+		h.Reset()
+		h.Write(a)
+		a = h.Sum(nil)
+		h.Reset()
+		h.Write(a)
+		h.Write(seed)
+		r = h.Sum(r)
+	}
+	return r[:n]
+}
+func newGCM(k []byte) (cipher.AEAD, error) {
+	b, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(b)
+}
+func (c *dtlsConn) record(e uint16, t uint8, b []byte) []byte {
+	n := uint64(e)<<48 | c.seq[e]&0xFFFFFFFFFFFF
+	c.seq[e]++
+	r := make([]byte, 13, 13+8+len(b)+16)
+	r[0], r[1], r[2] = t, 0xFE, 0xFD
+	binary.BigEndian.PutUint64(r[3:], n)
+	if e == 0 {
+		binary.BigEndian.PutUint16(r[11:], uint16(len(b)))
+		return append(r, b...)
+	}
+	var (
+		v [12]byte
+		a [13]byte
+	)
+	copy(v[:], c.wIV[:])
+	copy(v[4:], r[3:11])
+	copy(a[:], r[3:11])
+	a[8], a[9], a[10] = t, 0xFE, 0xFD
+	binary.BigEndian.PutUint16(a[11:], uint16(len(b)))
+	r = append(r, r[3:11]...)
+	r = c.w.Seal(r, v[:], b, a[:])
+	binary.BigEndian.PutUint16(r[11:], uint16(len(r)-13))
+	return r
+}
+func (c *dtlsConn) open(h, b []byte) ([]byte, error) {
+	if c.r == nil || len(b) < 8+16 {
+		return nil, errHandshake
+	}
+	var (
+		v [12]byte
+		a [13]byte
+	)
+	copy(v[:], c.rIV[:])
+	copy(v[4:], b[:8])
+	copy(a[:], h[3:11])
+	a[8], a[9], a[10] = h[0], h[1], h[2]
+	binary.BigEndian.PutUint16(a[11:], uint16(len(b)-8-16))
+	return c.r.Open(nil, v[:], b[8:], a[:])
+}
+
+// dtlsClient will complete a DTLS handshake over the supplied connection using
+// the provided PSK identity and key.
+//
+// The handshake will be retried if no response is received and will stop when
+// the supplied Context is canceled.
+func dtlsClient(x context.Context, c net.Conn, id string, psk []byte) (*dtlsConn, error) {
+	h := &dtlsState{dtlsConn: &dtlsConn{conn: c}, hash: sha256.New()}
+	if _, err := rand.Read(h.random[:32]); err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint32(h.random[0:], uint32(time.Now().Unix()))
+	var (
+		hello, verify bool
+		cookie        []byte
+		f             = func(t uint8, m []byte) (bool, error) {
+			switch t {
+			case dtlsServerHello:
+				if len(m) < 35 || int(m[34])+38 > len(m) {
+					return false, errHandshake
+				}
+				copy(h.random[32:], m[2:34])
+				if s := binary.BigEndian.Uint16(m[35+m[34]:]); s != dtlsSuite {
+					return false, &errval{s: "server selected unsupported cipher suite 0x" + strconv.FormatUint(uint64(s), 16)}
+				}
+				hello = true
+			case dtlsServerKeyExchange:
+			case dtlsServerHelloDone:
+				return hello, nil
+			default:
+				return false, errHandshake
+			}
+			return false, nil
+		}
+	)
+	m := h.message(dtlsClientHello, h.hello(nil))
+	err := h.exchange(x, func() [][]byte { return [][]byte{h.record(0, dtlsHandshake, m)} }, func(t uint8, m []byte) (bool, error) {
+		if t != dtlsHelloVerify || hello {
+			return f(t, m)
+		}
+		if len(m) < 3 || int(m[2])+3 > len(m) {
+			return false, errHandshake
+		}
+		cookie, verify = append([]byte{}, m[3:3+m[2]]...), true
+		return true, nil
+	})
+	if err == nil && verify {
+		// The first ClientHello and the HelloVerifyRequest are not part of the
+		// handshake hash.
+		h.hash.Reset()
+		m = h.message(dtlsClientHello, h.hello(cookie))
+		err = h.exchange(x, func() [][]byte { return [][]byte{h.record(0, dtlsHandshake, m)} }, f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// PSK pre-master secret: uint16(N) | N zero bytes | uint16(N) | PSK
+	p := make([]byte, 4+len(psk)*2)
+	binary.BigEndian.PutUint16(p, uint16(len(psk)))
+	binary.BigEndian.PutUint16(p[2+len(psk):], uint16(len(psk)))
+	copy(p[4+len(psk):], psk)
+	h.master = prf(p, "master secret", h.random[:], 48)
+	k := prf(h.master, "key expansion", append(append([]byte{}, h.random[32:]...), h.random[:32]...), 40)
+	if h.pend, err = newGCM(k[16:32]); err != nil {
+		return nil, err
+	}
+	if h.w, err = newGCM(k[:16]); err != nil {
+		return nil, err
+	}
+	copy(h.wIV[:], k[32:36])
+	copy(h.rIV[:], k[36:40])
+	e := make([]byte, 2+len(id))
+	binary.BigEndian.PutUint16(e, uint16(len(id)))
+	copy(e[2:], id)
+	var (
+		m1 = h.message(dtlsClientKeyExchange, e)
+		m2 = h.message(dtlsFinished, prf(h.master, "client finished", h.hash.Sum(nil), 12))
+		v  = prf(h.master, "server finished", h.hash.Sum(nil), 12)
+	)
+	err = h.exchange(x, func() [][]byte {
+		return [][]byte{h.record(0, dtlsHandshake, m1), h.record(0, dtlsChangeCipher, []byte{1}), h.record(1, dtlsHandshake, m2)}
+	}, func(t uint8, m []byte) (bool, error) {
+		if t != dtlsFinished || h.r == nil || !hmac.Equal(m, v) {
+			return false, &errval{s: "server Finished message is invalid"}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h.dtlsConn, nil
+}
+func (h *dtlsState) hello(c []byte) []byte {
+	b := make([]byte, 0, 64+len(c))
+	b = append(b, 0xFE, 0xFD)
+	b = append(b, h.random[:32]...)
+	b = append(b, 0, byte(len(c)))
+	b = append(b, c...)
+	return append(b, 0, 2, byte(dtlsSuite>>8), byte(dtlsSuite), 1, 0)
+}
+func (h *dtlsState) message(t uint8, b []byte) []byte {
+	m := make([]byte, 12+len(b))
+	m[0] = t
+	put24(m[1:], len(b))
+	binary.BigEndian.PutUint16(m[4:], h.msg)
+	put24(m[9:], len(b))
+	copy(m[12:], b)
+	h.msg++
+	h.hash.Write(m)
+	return m
+}
+func (h *dtlsState) exchange(x context.Context, f func() [][]byte, g func(uint8, []byte) (bool, error)) error {
+	b := make([]byte, 2048)
+	for i, w := 0, time.Second; i < dtlsRetries; i, w = i+1, w*2 {
+		for _, r := range f() {
+			if _, err := h.conn.Write(r); err != nil {
+				return err
+			}
+		}
+		d := time.Now().Add(w)
+		if v, ok := x.Deadline(); ok && v.Before(d) {
+			d = v
+		}
+		h.conn.SetReadDeadline(d)
+		for {
+			n, err := h.conn.Read(b)
+			if err != nil {
+				if e, ok := err.(net.Error); ok && e.Timeout() {
+					break
+				}
+				return err
+			}
+			if ok, err := h.records(b[:n], g); err != nil || ok {
+				h.conn.SetReadDeadline(time.Time{})
+				return err
+			}
+		}
+		if err := x.Err(); err != nil {
+			return err
+		}
+	}
+	return &errval{s: "DTLS handshake timed out"}
+}
+func (h *dtlsState) records(b []byte, g func(uint8, []byte) (bool, error)) (bool, error) {
+	for len(b) >= 13 {
+		n := int(binary.BigEndian.Uint16(b[11:])) + 13
+		if n > len(b) {
+			return false, nil
+		}
+		r, d := b[:13], b[13:n]
+		b = b[n:]
+		if e := binary.BigEndian.Uint16(r[3:]); e > 0 {
+			if e != 1 || h.r == nil {
+				continue
+			}
+			var err error
+			if d, err = h.open(r, d); err != nil {
+				return false, err
+			}
+		}
+		switch r[0] {
+		case dtlsAlert:
+			if len(d) < 2 {
+				return false, errHandshake
+			}
+			return false, &errval{s: "received DTLS alert " + strconv.Itoa(int(d[1]))}
+		case dtlsChangeCipher:
+			if h.pend != nil {
+				h.r, h.pend = h.pend, nil
+			}
+			continue
+		case dtlsHandshake:
+		default:
+			continue
+		}
+		for len(d) >= 12 {
+			var (
+				l = get24(d[1:])
+				s = binary.BigEndian.Uint16(d[4:])
+				o = get24(d[6:])
+				z = get24(d[9:])
+			)
+			if z+12 > len(d) {
+				return false, errHandshake
+			}
+			m := d[:12+z]
+			if d = d[12+z:]; s != h.next {
+				continue
+			}
+			if o != 0 || z != l {
+				return false, &errval{s: "fragmented DTLS handshake messages are not supported"}
+			}
+			if h.next++; m[0] != dtlsHelloVerify && m[0] != dtlsFinished {
+				h.hash.Write(m)
+			}
+			ok, err := g(m[0], m[12:])
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+	return false, nil
+}
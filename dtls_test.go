@@ -0,0 +1,228 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+const testStreamID = "1a8d99cc-967b-44f2-9202-43f976c0fa6b"
+
+// testDTLS is a stand-in DTLS server that completes the server side of a
+// PSK handshake and returns the application data records it receives.
+type testDTLS struct {
+	*dtlsState
+	conn   net.PacketConn
+	addr   net.Addr
+	id     string
+	psk    []byte
+	cookie bool
+}
+
+func (s *testDTLS) read() ([]byte, []byte, error) {
+	b := make([]byte, 2048)
+	n, a, err := s.conn.ReadFrom(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.addr = a; n < 13 || int(binary.BigEndian.Uint16(b[11:]))+13 != n {
+		return nil, nil, errors.New("received an invalid record")
+	}
+	h, d := b[:13], b[13:n]
+	if binary.BigEndian.Uint16(h[3:]) == 0 {
+		return h, d, nil
+	}
+	d, err = s.open(h, d)
+	return h, d, err
+}
+func (s *testDTLS) write(r ...[]byte) error {
+	_, err := s.conn.WriteTo(bytes.Join(r, nil), s.addr)
+	return err
+}
+func (s *testDTLS) message(t uint8) ([]byte, error) {
+	h, d, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	if h[0] != dtlsHandshake || len(d) < 12 || d[0] != t || get24(d[9:])+12 != len(d) {
+		return nil, errors.New("received an unexpected handshake message")
+	}
+	return d, nil
+}
+func (s *testDTLS) handshake() error {
+	m, err := s.message(dtlsClientHello)
+	if err != nil {
+		return err
+	}
+	if s.cookie {
+		s.write(s.record(0, dtlsHandshake, s.dtlsState.message(dtlsHelloVerify, []byte{0xFE, 0xFD, 4, 1, 2, 3, 4})))
+		if m, err = s.message(dtlsClientHello); err != nil {
+			return err
+		}
+		if !bytes.Contains(m[12+35:], []byte{4, 1, 2, 3, 4}) {
+			return errors.New("second ClientHello does not contain the cookie")
+		}
+		s.hash.Reset()
+	}
+	s.hash.Write(m)
+	copy(s.random[:32], m[14:46])
+	rand.Read(s.random[32:])
+	v := append(append([]byte{0xFE, 0xFD}, s.random[32:]...), 0, byte(dtlsSuite>>8), byte(dtlsSuite), 0)
+	err = s.write(
+		s.record(0, dtlsHandshake, s.dtlsState.message(dtlsServerHello, v)),
+		s.record(0, dtlsHandshake, s.dtlsState.message(dtlsServerHelloDone, nil)),
+	)
+	if err != nil {
+		return err
+	}
+	if m, err = s.message(dtlsClientKeyExchange); err != nil {
+		return err
+	}
+	if string(m[14:]) != s.id {
+		return errors.New("received an invalid PSK identity " + string(m[14:]))
+	}
+	s.hash.Write(m)
+	p := make([]byte, 4+len(s.psk)*2)
+	binary.BigEndian.PutUint16(p, uint16(len(s.psk)))
+	binary.BigEndian.PutUint16(p[2+len(s.psk):], uint16(len(s.psk)))
+	copy(p[4+len(s.psk):], s.psk)
+	s.master = prf(p, "master secret", s.random[:], 48)
+	k := prf(s.master, "key expansion", append(append([]byte{}, s.random[32:]...), s.random[:32]...), 40)
+	if s.r, err = newGCM(k[:16]); err != nil {
+		return err
+	}
+	if s.w, err = newGCM(k[16:32]); err != nil {
+		return err
+	}
+	copy(s.rIV[:], k[32:36])
+	copy(s.wIV[:], k[36:40])
+	if h, _, err := s.read(); err != nil || h[0] != dtlsChangeCipher {
+		return errors.New("expected a ChangeCipherSpec record")
+	}
+	if m, err = s.message(dtlsFinished); err != nil {
+		return err
+	}
+	if !hmac.Equal(m[12:], prf(s.master, "client finished", s.hash.Sum(nil), 12)) {
+		return errors.New("client Finished message is invalid")
+	}
+	s.hash.Write(m)
+	v = prf(s.master, "server finished", s.hash.Sum(nil), 12)
+	return s.write(s.record(0, dtlsChangeCipher, []byte{1}), s.record(1, dtlsHandshake, s.dtlsState.message(dtlsFinished, v)))
+}
+func testStream(t *testing.T, id string, cookie bool) (*Stream, <-chan []byte) {
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %s", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	var (
+		p = []byte("0123456789abcdef")
+		s = &testDTLS{dtlsState: &dtlsState{dtlsConn: new(dtlsConn), hash: sha256.New()}, conn: l, id: "streamuser", psk: p, cookie: cookie}
+		e = make(chan error, 1)
+		o = make(chan []byte, 16)
+	)
+	l.SetReadDeadline(time.Now().Add(10 * time.Second))
+	go func() {
+		err := s.handshake()
+		if e <- err; err != nil {
+			return
+		}
+		for {
+			h, d, err := s.read()
+			if err != nil {
+				close(o)
+				return
+			}
+			if h[0] == dtlsData {
+				o <- d
+			}
+		}
+	}()
+	c, err := net.Dial("udp", l.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %s", err)
+	}
+	x, f := context.WithTimeout(context.Background(), 10*time.Second)
+	defer f()
+	v, err := NewStream(x, c, s.id, hex.EncodeToString(p), id)
+	if err != nil {
+		t.Fatalf("NewStream failed: %s", err)
+	}
+	t.Cleanup(func() { v.Close() })
+	if err = <-e; err != nil {
+		t.Fatalf("server handshake failed: %s", err)
+	}
+	return v, o
+}
+func checkFrame(t *testing.T, b []byte, v uint8, q uint8, n int) []byte {
+	if !bytes.HasPrefix(b, []byte("HueStream")) || len(b) < 16 {
+		t.Fatalf("frame %x does not have a HueStream header", b)
+	}
+	if b[9] != v || b[11] != q || b[14] != byte(StreamRGB) {
+		t.Fatalf("frame header %x is invalid, want version %d and sequence %d", b[:16], v, q)
+	}
+	if b = b[16:]; v == 2 {
+		if string(b[:36]) != testStreamID {
+			t.Fatalf("frame has the ID %q, want %q", b[:36], testStreamID)
+		}
+		b = b[36:]
+	}
+	if s := 9 - int(v-1)*2; len(b) != n*s {
+		t.Fatalf("frame has %d bytes of channels, want %d channels", len(b), n)
+	}
+	return b
+}
+func TestStreamV1(t *testing.T) {
+	s, o := testStream(t, "", false)
+	for i := uint16(1); i <= 12; i++ {
+		s.SetRGB(i, 0xFF, 0x80, uint8(i))
+	}
+	if err := s.Send(); err != nil {
+		t.Fatalf("Send failed: %s", err)
+	}
+	b := checkFrame(t, <-o, 1, 0, 10)
+	if !bytes.Equal(b[:9], []byte{0, 0, 1, 0xFF, 0xFF, 0x80, 0x80, 1, 1}) {
+		t.Fatalf("frame channel %x is invalid", b[:9])
+	}
+	b = checkFrame(t, <-o, 1, 1, 2)
+	if !bytes.Equal(b[9:], []byte{0, 0, 12, 0xFF, 0xFF, 0x80, 0x80, 12, 12}) {
+		t.Fatalf("frame channel %x is invalid", b[9:])
+	}
+}
+func TestStreamV2(t *testing.T) {
+	s, o := testStream(t, testStreamID, true)
+	s.SetRGB(0, 0xFF, 0, 0)
+	if err := s.SetColor(3, "blue"); err != nil {
+		t.Fatalf("SetColor failed: %s", err)
+	}
+	if err := s.Send(); err != nil {
+		t.Fatalf("Send failed: %s", err)
+	}
+	b := checkFrame(t, <-o, 2, 0, 2)
+	if !bytes.Equal(b, []byte{0, 0xFF, 0xFF, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0xFF, 0xFF}) {
+		t.Fatalf("frame channels %x are invalid", b)
+	}
+}
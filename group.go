@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 )
 
 const (
@@ -68,6 +69,18 @@ const (
 	All
 )
 
+const (
+	// GamutCommon is a GamutMode that will pick a single XY color point that is
+	// reachable by every Light in a Group with mixed color gamuts. This only
+	// requires a single command to be sent to the Bridge.
+	GamutCommon GamutMode = iota
+	// GamutPerLight is a GamutMode that will send the requested XY color point
+	// to each Light in a Group with mixed color gamuts separately, clamped to
+	// the gamut of each Light. This requires a command to be sent to the Bridge
+	// for every color Light in the Group.
+	GamutPerLight
+)
+
 // Room Class Type Constants
 const (
 	ClassInvalid GroupClass = 0
@@ -128,13 +141,38 @@ type Group struct {
 	action   controlState
 	mask     uint16
 
+	locations map[string]Location
+	Streaming StreamStatus
+
 	On, AllOn, Manual bool
 
-	Type  groupType
-	class GroupClass
+	Type      groupType
+	class     GroupClass
+	GamutMode GamutMode
 }
 type groupType uint8
 
+// Location is the position of a Light in an Entertainment Group. Each value is
+// between -1 and 1. The 'X' value is left to right, the 'Y' value is front to
+// back and the 'Z' value is bottom to top.
+type Location struct {
+	X, Y, Z float32
+}
+
+// StreamStatus is the Entertainment streaming state of a Group. The 'Owner' value
+// is the access key (username) of the application that is streaming, and is
+// empty if 'Active' is false.
+type StreamStatus struct {
+	Owner     string `json:"owner"`
+	ProxyMode string `json:"proxymode"`
+	ProxyNode string `json:"proxynode"`
+	Active    bool   `json:"active"`
+}
+
+// GamutMode is an integer representation that is used to determine how a Group
+// will set the color of Lights with differing color gamuts.
+type GamutMode uint8
+
 // GroupClass is an integer representation that is used to represent the Group
 // classification and can be used to determine the display icon in the Hue app.
 type GroupClass uint8
@@ -267,6 +305,275 @@ func (g *Group) SetName(n string) error {
 	}
 	return g.UpdateContext(g.bridge.ctx)
 }
+
+// CreateGroup will create a new Group on the Bridge with the specified name, type
+// and Lights. Only LightGroup, Room, Zone and Entertainment Groups can be created.
+//
+// This function returns an error wrapping 'ErrLimit' if the Bridge cannot store
+// any more Groups.
+func (b *Bridge) CreateGroup(x context.Context, n string, t groupType, l ...*Light) (*Group, error) {
+	switch t {
+	case LightGroup, Room, Zone, Entertainment:
+	default:
+		return nil, &errval{s: `cannot create a "` + t.String() + `" group`}
+	}
+	if len(n) == 0 || len(n) > 32 {
+		return nil, &errval{s: `invalid Group name "` + n + `"`}
+	}
+	i := make([]string, len(l))
+	for k := range l {
+		i[k] = l[k].ID
+	}
+	v, err := b.createGroup(x, n, t, i)
+	if err != nil {
+		return nil, err
+	}
+	r, err := b.request(x, http.MethodGet, "/groups/"+v, nil)
+	if err != nil {
+		return nil, err
+	}
+	g := new(Group)
+	b.lock.Lock()
+	if err = g.unmarshal(v, b, r); err == nil && b.groups != nil {
+		b.groups[v] = g
+	}
+	b.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+func (b *Bridge) createGroup(x context.Context, n string, t groupType, i []string) (string, error) {
+	if err := b.limit(x, "Group", 1, func(c *Capabilities) Capacity { return c.Groups }); err != nil {
+		return "", err
+	}
+	d, err := json.Marshal(map[string]interface{}{"name": n, "type": t, "lights": i})
+	if err != nil {
+		return "", err
+	}
+	r, err := b.request(x, http.MethodPost, "/groups", d)
+	if err != nil {
+		return "", err
+	}
+	var v struct {
+		ID string `json:"id"`
+	}
+	if err = json.Unmarshal(r, &v); err != nil || len(v.ID) == 0 {
+		return "", &errval{s: `could not parse created Group ID`, e: err}
+	}
+	return v.ID, nil
+}
+
+// Locations returns a copy of the Light locations of this Entertainment Group,
+// mapped by the Light ID.
+//
+// This function returns an empty map if the Group is not an Entertainment Group.
+func (g *Group) Locations() map[string]Location {
+	r := make(map[string]Location, len(g.locations))
+	for k, v := range g.locations {
+		r[k] = v
+	}
+	return r
+}
+
+// Location returns the location of the Light with the specified ID in this
+// Entertainment Group. The boolean is false if the Light has no location.
+func (g *Group) Location(id string) (Location, bool) {
+	v, ok := g.locations[id]
+	return v, ok
+}
+
+// SetLocation will change the location of the Light with the specified ID in
+// this Entertainment Group. Values are clamped between -1 and 1. This function
+// returns any errors during setting the location.
+//
+// The location of every Light in the Group is sent at once, so multiple
+// locations can be changed with a single request when 'Manual' is "true".
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (g *Group) SetLocation(id string, v Location) error {
+	if g.Type != Entertainment {
+		return &errval{s: `group "` + g.ID + `" is not an Entertainment group`}
+	}
+	if g.locations == nil {
+		g.locations = make(map[string]Location)
+	}
+	g.locations[id] = Location{X: clampUnit(v.X), Y: clampUnit(v.Y), Z: clampUnit(v.Z)}
+	if g.mask |= maskLocation; g.Manual {
+		return nil
+	}
+	return g.UpdateContext(g.bridge.ctx)
+}
+func clampUnit(v float32) float32 {
+	switch {
+	case v < -1:
+		return -1
+	case v > 1:
+		return 1
+	}
+	return v
+}
+
+// MarshalJSON will transform the Location into a JSON array.
+func (l Location) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]float32{l.X, l.Y, l.Z})
+}
+
+// UnmarshalJSON will read the Location from a JSON array. Older Bridges only
+// return the 'X' and 'Y' values.
+func (l *Location) UnmarshalJSON(d []byte) error {
+	var v []float32
+	if err := json.Unmarshal(d, &v); err != nil {
+		return err
+	}
+	if len(v) < 2 {
+		return &errval{s: `invalid Location value`}
+	}
+	if l.X, l.Y, l.Z = v[0], v[1], 0; len(v) > 2 {
+		l.Z = v[2]
+	}
+	return nil
+}
+
+// Apply will send the values set in the LightState to the Group. Only the values
+// that have been set on the LightState will be sent.
+//
+// XY color values and color temperature values are handled the same way as the
+// 'SetXY' and 'SetTemperature' functions.
+//
+// This function does not use the 'Manual' attribute and will not send any
+// changes that are waiting for an 'Update*' function call.
+func (g *Group) Apply(x context.Context, s LightState) error {
+	if s.mask == 0 {
+		return nil
+	}
+	m, t := g.mask, g.action.Transition
+	g.action.merge(s.controlState, s.mask)
+	g.action.Transition, g.mask = s.Transition, s.mask
+	err := g.UpdateContext(x)
+	if g.action.Transition, g.mask = t, m; err != nil {
+		return err
+	}
+	g.mask &^= s.mask
+	return nil
+}
+
+// SetKelvin will set the light color temperature of the Group to the specified
+// Kelvin value. The value is converted to mireds and clamped to the widest color
+// temperature range supported by the Lights in the Group.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (g *Group) SetKelvin(k uint16) error {
+	var r ctRange
+	for _, l := range g.Lights {
+		if l.ct == nil {
+			continue
+		}
+		if r.Min == 0 || l.ct.Min < r.Min {
+			r.Min = l.ct.Min
+		}
+		if l.ct.Max > r.Max {
+			r.Max = l.ct.Max
+		}
+	}
+	if r.Max == 0 {
+		r = *defaultRange
+	}
+	return g.SetTemperature(r.clamp(mireds(k)))
+}
+
+// SetXY will set the light color of the Group to the specified CIE 1931 XY
+// value.
+//
+// If the Lights in the Group have mixed color gamuts, the 'GamutMode' value is
+// used to determine how the color is sent.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (g *Group) SetXY(x float32, y float32) error {
+	g.action.XY[0], g.action.XY[1] = x, y
+	if g.mask |= maskXY; g.Manual {
+		return nil
+	}
+	return g.UpdateContext(g.bridge.ctx)
+}
+
+// SetHex will set the color of the Group to the specified hex string value.
+//
+// If the Lights in the Group have mixed color gamuts, the 'GamutMode' value is
+// used to determine how the color is sent.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+//
+// Hex strings MUST be formalized with 3, 4, 6 or 8 characters and may begin with
+// a '#' symbol. Any alpha value is ignored.
+func (g *Group) SetHex(h string) error {
+	x, y, err := xyFromHex(*fullGamut, h)
+	if err != nil {
+		return err
+	}
+	return g.SetXY(x, y)
+}
+
+// SetColor will set the color of the Group to the specified CSS color string.
+//
+// If the Lights in the Group have mixed color gamuts, the 'GamutMode' value is
+// used to determine how the color is sent.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+//
+// See 'ParseColor' for the supported formats.
+func (g *Group) SetColor(c string) error {
+	x, y, err := xyFromString(*fullGamut, c)
+	if err != nil {
+		return err
+	}
+	return g.SetXY(x, y)
+}
+
+// SetRGB will set the light color of the Group to the specified RGB value.
+//
+// If the Lights in the Group have mixed color gamuts, the 'GamutMode' value is
+// used to determine how the color is sent.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (g *Group) SetRGB(r uint8, gr uint8, b uint8) error {
+	x, y := xyFromRGB(*fullGamut, r, gr, b)
+	return g.SetXY(x, y)
+}
+
+// SetTemperature will set the light color temperature of the Group to the
+// specified value in mireds.
+//
+// Any Lights in the Group that support color but not color temperature will be
+// sent the equivalent XY color instead, so mixed Groups will match visually.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (g *Group) SetTemperature(t uint16) error {
+	g.action.Temperature = t
+	if g.mask |= maskTemperature; g.Manual {
+		return nil
+	}
+	return g.UpdateContext(g.bridge.ctx)
+}
 func (t groupType) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + t.String() + `"`), nil
 }
@@ -404,27 +711,150 @@ func (g *Group) UpdateContext(x context.Context) error {
 		}
 		return g.unmarshal(g.ID, g.bridge, r)
 	}
-	if g.mask&maskName != 0 {
-		b, err := json.Marshal(map[string]string{"name": g.name})
+	if g.mask&(maskName|maskLocation) != 0 {
+		v := make(map[string]interface{}, 2)
+		if g.mask&maskName != 0 {
+			v["name"] = g.name
+		}
+		if g.mask&maskLocation != 0 {
+			v["locations"] = g.locations
+		}
+		b, err := json.Marshal(v)
 		if err != nil {
 			return err
 		}
 		if _, err = g.bridge.request(x, http.MethodPut, "/groups/"+g.ID, b); err != nil {
 			return err
 		}
-		if g.mask = g.mask &^ maskName; g.mask == 0 {
+		if g.mask = g.mask &^ (maskName | maskLocation); g.mask == 0 {
 			return nil
 		}
 	}
-	b, err := g.action.marshal(g.mask)
-	if err != nil {
-		return err
+	m := g.mask
+	if m&maskXY != 0 {
+		if g.mixed() {
+			m &^= maskXY
+		} else {
+			g.clampXY()
+		}
 	}
-	if _, err = g.bridge.request(x, http.MethodPut, "/groups/"+g.ID+"/action", b); err != nil {
-		return err
+	if m != 0 {
+		b, err := g.action.marshal(m)
+		if err != nil {
+			return err
+		}
+		if _, err = g.bridge.request(x, http.MethodPut, "/groups/"+g.ID+"/action", b); err != nil {
+			return err
+		}
+	}
+	if m&maskXY == 0 && g.mask&maskXY != 0 {
+		if err := g.splitXY(x); err != nil {
+			return err
+		}
+	}
+	if g.mask&maskTemperature != 0 {
+		if err := g.emulateTemperature(x); err != nil {
+			return err
+		}
 	}
 	g.mask = 0
-	return err
+	return nil
+}
+func (g *Group) mixed() bool {
+	// Returns true if the Lights in the Group have mixed gamuts and
+	// 'GamutPerLight' is set, which means the XY value must be sent to each
+	// Light instead of the whole Group.
+	return g.GamutMode == GamutPerLight && len(g.gamuts()) > 1
+}
+func (g *Group) clampXY() {
+	// Clamp the XY value sent to the whole Group to the gamut of the Lights.
+	// If the Lights have mixed gamuts, the XY value is clamped to the nearest
+	// point that is inside all of them.
+	switch c := g.gamuts(); len(c) {
+	case 0:
+		g.action.XY[0], g.action.XY[1] = defaultGamut.clamp(g.action.XY[0], g.action.XY[1])
+	case 1:
+		g.action.XY[0], g.action.XY[1] = c[0].clamp(g.action.XY[0], g.action.XY[1])
+	default:
+		g.action.XY[0], g.action.XY[1] = common(c, g.action.XY[0], g.action.XY[1])
+	}
+}
+func (g *Group) gamuts() []gamut {
+	var c []gamut
+	for _, l := range g.Lights {
+		v := l.colorGamut()
+		if v == nil {
+			continue
+		}
+		n := true
+		for i := range c {
+			if c[i] == *v {
+				n = false
+				break
+			}
+		}
+		if n {
+			c = append(c, *v)
+		}
+	}
+	return c
+}
+func (g *Group) splitXY(x context.Context) error {
+	var t time.Time
+	for _, l := range g.Lights {
+		c := l.colorGamut()
+		if c == nil {
+			continue
+		}
+		var s controlState
+		s.XY[0], s.XY[1] = c.clamp(g.action.XY[0], g.action.XY[1])
+		s.Transition = g.action.Transition
+		b, err := s.marshal(maskXY)
+		if err != nil {
+			return err
+		}
+		if err = wait(x, t, rateLight); err != nil {
+			return err
+		}
+		_, err = g.bridge.request(x, http.MethodPut, "/lights/"+l.ID+"/state", b)
+		if t = time.Now(); err != nil {
+			return err
+		}
+		l.state.merge(s, maskXY)
+		l.known.merge(s, maskXY)
+	}
+	return nil
+}
+func (g *Group) emulateTemperature(x context.Context) error {
+	// Lights without color temperature support ignore the "ct" value sent to
+	// the Group, so they need the matching XY value sent to them directly.
+	var t time.Time
+	for _, l := range g.Lights {
+		if l.ct != nil {
+			continue
+		}
+		c := l.colorGamut()
+		if c == nil {
+			continue
+		}
+		var s controlState
+		s.XY[0], s.XY[1] = xyFromTemperature(*c, g.action.Temperature)
+		s.Transition = g.action.Transition
+		b, err := s.marshal(maskXY)
+		if err != nil {
+			return err
+		}
+		if err = wait(x, t, rateLight); err != nil {
+			return err
+		}
+		_, err = g.bridge.request(x, http.MethodPut, "/lights/"+l.ID+"/state", b)
+		if t = time.Now(); err != nil {
+			return err
+		}
+		l.state.merge(s, maskXY)
+		l.known.merge(s, maskXY)
+	}
+	return nil
 }
 func (g *Group) unmarshal(i string, b *Bridge, d []byte) error {
 	var (
@@ -484,6 +914,19 @@ func (g *Group) unmarshal(i string, b *Bridge, d []byte) error {
 			}
 		}
 	}
+	if v, ok = m["locations"]; ok {
+		g.locations = nil
+		if err := json.Unmarshal(v, &g.locations); err != nil {
+			return err
+		}
+	}
+	if v, ok = m["stream"]; ok {
+		// The 'owner' and 'proxynode' values may be null.
+		g.Streaming = StreamStatus{}
+		if err := json.Unmarshal(v, &g.Streaming); err != nil {
+			return err
+		}
+	}
 	if v, ok = m["state"]; ok {
 		if err := json.Unmarshal(v, &m); err != nil {
 			return err
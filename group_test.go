@@ -0,0 +1,65 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"testing"
+	"time"
+)
+
+const testGroupLights = `{
+"1":{"name":"A","uniqueid":"00:17:88:01:00:00:00:01-0b","type":"Color light","modelid":"X1","productname":"Color lamp","state":{"on":true,"bri":1,"hue":0,"sat":0,"xy":[0.3,0.3],"alert":"none","effect":"none","colormode":"xy","reachable":true}},
+"2":{"name":"B","uniqueid":"00:17:88:01:00:00:00:02-0b","type":"Color light","modelid":"X1","productname":"Color lamp","state":{"on":true,"bri":1,"hue":0,"sat":0,"xy":[0.3,0.3],"alert":"none","effect":"none","colormode":"xy","reachable":true},"capabilities":{"control":{` + testGamut + `}}}
+}`
+
+func testGroup(t *testing.T) (*Group, *testServer) {
+	b, s := newTestBridge(t, map[string]string{
+		"GET /lights":  testGroupLights,
+		"GET /sensors": testSensors,
+		"GET /groups":  `{"1":{"name":"Room","type":"Room","lights":["1","2"],"action":{"on":true,"alert":"none"}}}`,
+	})
+	if _, err := b.Lights(); err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	g := b.Group("1")
+	if g == nil || len(g.Lights) != 2 {
+		t.Fatalf("Group did not return the Group with both Lights")
+	}
+	return g, s
+}
+func TestGroupEmulateTemperature(t *testing.T) {
+	g, s := testGroup(t)
+	n := time.Now()
+	if err := g.SetTemperature(370); err != nil {
+		t.Fatalf("SetTemperature failed: %s", err)
+	}
+	if d := time.Since(n); d < rateLight {
+		t.Errorf("SetTemperature took %s, want the Light commands to be at least %s apart", d, rateLight)
+	}
+	checkRequests(t, s, `PUT /groups/1/action {"ct":370}`, "PUT /lights/", "PUT /lights/")
+}
+func TestGroupSplitXY(t *testing.T) {
+	g, s := testGroup(t)
+	g.GamutMode = GamutPerLight
+	n := time.Now()
+	if err := g.SetXY(0.7, 0.3); err != nil {
+		t.Fatalf("SetXY failed: %s", err)
+	}
+	if d := time.Since(n); d < rateLight {
+		t.Errorf("SetXY took %s, want the Light commands to be at least %s apart", d, rateLight)
+	}
+	checkRequests(t, s, "PUT /lights/1/state ", "PUT /lights/2/state ")
+}
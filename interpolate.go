@@ -0,0 +1,232 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// BlendXY is a Blend mode that interpolates linearly on the CIE 1931 XY axis.
+	// This is the same method used by the Bridge for transitions.
+	BlendXY Blend = iota
+	// BlendLab is a Blend mode that interpolates in the CIELAB color space, which
+	// avoids most of the washed-out midpoints of XY interpolation.
+	BlendLab
+	// BlendOKLab is a Blend mode that interpolates in the OKLab color space, which
+	// is more perceptually uniform than CIELAB, especially for blue hues.
+	BlendOKLab
+)
+
+// Blend represents the color space used to interpolate between two LightStates.
+type Blend uint8
+
+// Interpolate will generate a sequence of 'n' LightStates that fade from the
+// LightState 'a' to the LightState 'b' using the specified Blend mode. The
+// returned slice does not contain 'a' and the last entry will match 'b'.
+//
+// Colors set using XY or temperature values are interpolated, along with the
+// brightness. If only one LightState has a color or brightness set, that value
+// is used for every step. The Transition time of 'b' is divided evenly across
+// every step. If 'b' turns the Light on, every step will also turn the Light on.
+//
+// The generated colors are clamped to the default color gamut. Use the
+// 'Light.Interpolate' function to clamp them to the gamut of a specific Light.
+func Interpolate(a, b LightState, n int, m Blend) []LightState {
+	return interpolate(*defaultGamut, a, b, n, m)
+}
+func lab(x, y, z float64) (float64, float64, float64) {
+	var (
+		f = func(t float64) float64 {
+			if t > 216.0/24389.0 {
+				return math.Cbrt(t)
+			}
+			return t*(24389.0/27.0)/116 + 16.0/116.0
+		}
+		fx = f(x / 0.95047)
+		fy = f(y)
+		fz = f(z / 1.08883)
+	)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+func unlab(l, a, b float64) (float64, float64, float64) {
+	var (
+		f = func(t float64) float64 {
+			if t > 6.0/29.0 {
+				return t * t * t
+			}
+			return 3 * (6.0 / 29.0) * (6.0 / 29.0) * (t - 4.0/29.0)
+		}
+		fy = (l + 16) / 116
+	)
+	return f(fy+a/500) * 0.95047, f(fy), f(fy-b/200) * 1.08883
+}
+func oklab(x, y, z float64) (float64, float64, float64) {
+	var (
+		l = math.Cbrt(0.8189330101*x + 0.3618667424*y - 0.1288597137*z)
+		m = math.Cbrt(0.0329845436*x + 0.9293118715*y + 0.0361456387*z)
+		s = math.Cbrt(0.0482003018*x + 0.2643662691*y + 0.6338517070*z)
+	)
+	return 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+}
+func unoklab(v, a, b float64) (float64, float64, float64) {
+	var (
+		l = v + 0.3963377774*a + 0.2158037573*b
+		m = v - 0.1055613458*a - 0.0638541728*b
+		s = v - 0.0894841775*a - 1.2914855480*b
+	)
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return 1.2270138511*l - 0.5577999807*m + 0.2812561490*s,
+		-0.0405801784*l + 1.1122568696*m - 0.0716766787*s,
+		-0.0763812845*l - 0.4214819784*m + 1.5861632204*s
+}
+
+// Interpolate will generate a sequence of 'n' LightStates that fade from the
+// LightState 'a' to the LightState 'b' using the specified Blend mode. The
+// returned slice does not contain 'a' and the last entry will match 'b'.
+//
+// This function acts the same as the package 'Interpolate' function, except the
+// generated colors are clamped to the color gamut of the Light.
+func (l *Light) Interpolate(a, b LightState, n int, m Blend) []LightState {
+	if l.gamut == nil {
+		return interpolate(*defaultGamut, a, b, n, m)
+	}
+	return interpolate(*l.gamut, a, b, n, m)
+}
+func (s LightState) color() (point, bool) {
+	switch {
+	case s.mask&maskXY != 0:
+		return s.XY, true
+	case s.mask&maskTemperature != 0:
+		var p point
+		p[0], p[1] = xyFromTemperature(*fullGamut, s.Temperature)
+		return p, true
+	}
+	return point{}, false
+}
+func interpolate(c gamut, a, b LightState, n int, m Blend) []LightState {
+	if n <= 0 {
+		return nil
+	}
+	var (
+		o      = make([]LightState, n)
+		t      = time.Duration(b.Transition) * (time.Millisecond * 100) / time.Duration(n)
+		j, x   = a.color()
+		k, y   = b.color()
+		u, v   = float64(a.Brightness), float64(b.Brightness)
+		hu, hv = a.mask&maskBrightness != 0, b.mask&maskBrightness != 0
+		p1, p2 [3]float64
+		hc, br = x || y, hu || hv
+	)
+	switch {
+	case !x:
+		j = k
+	case !y:
+		k = j
+	}
+	switch {
+	case !hu:
+		u = v
+	case !hv:
+		v = u
+	}
+	if hc {
+		p1, p2 = blendIn(m, j, u, br), blendIn(m, k, v, br)
+	}
+	for i := range o {
+		var (
+			s = &o[i]
+			f = float64(i+1) / float64(n)
+		)
+		if i+1 == n {
+			s.mask, s.controlState = b.mask, b.controlState
+			if s.SetTransition(t); hc && (!y || b.mask&maskXY != 0) {
+				s.XY[0], s.XY[1] = c.clamp(k[0], k[1])
+				s.mask |= maskXY
+			}
+			if br && !hv {
+				s.SetBrightness(uint8(v))
+			}
+			continue
+		}
+		if s.SetTransition(t); b.mask&maskOn != 0 && b.On {
+			s.SetOn(true)
+		}
+		if br {
+			s.SetBrightness(uint8(math.Round(u + (v-u)*f)))
+		}
+		if !hc {
+			continue
+		}
+		var (
+			q      = [3]float64{p1[0] + (p2[0]-p1[0])*f, p1[1] + (p2[1]-p1[1])*f, p1[2] + (p2[2]-p1[2])*f}
+			z, l   = blendOut(m, q)
+			px, py = c.clamp(float32(z[0]), float32(z[1]))
+		)
+		s.SetXY(px, py)
+		if br && m != BlendXY && l > 0 {
+			s.SetBrightness(uint8(math.Round(math.Min(l*254, 254))))
+		}
+	}
+	return o
+}
+func blendIn(m Blend, p point, b float64, v bool) [3]float64 {
+	if m == BlendXY {
+		return [3]float64{float64(p[0]), float64(p[1]), b}
+	}
+	var (
+		x, y = float64(p[0]), float64(p[1])
+		l    = 1.0
+	)
+	if v {
+		// Keep a tiny amount of luminance so that the color of an "off" endpoint
+		// is not lost during the conversion.
+		if l = b / 254; l < 0.001 {
+			l = 0.001
+		}
+	}
+	if y <= 0 {
+		y = 0.0001
+	}
+	var (
+		cx = x * l / y
+		cz = (1 - x - y) * l / y
+	)
+	if m == BlendLab {
+		r1, r2, r3 := lab(cx, l, cz)
+		return [3]float64{r1, r2, r3}
+	}
+	r1, r2, r3 := oklab(cx, l, cz)
+	return [3]float64{r1, r2, r3}
+}
+func blendOut(m Blend, q [3]float64) ([2]float64, float64) {
+	var x, y, z float64
+	switch m {
+	case BlendXY:
+		return [2]float64{q[0], q[1]}, q[2] / 254
+	case BlendLab:
+		x, y, z = unlab(q[0], q[1], q[2])
+	default:
+		x, y, z = unoklab(q[0], q[1], q[2])
+	}
+	if t := x + y + z; t > 0 {
+		return [2]float64{x / t, y / t}, y
+	}
+	return [2]float64{0.3127, 0.3290}, 0
+}
@@ -0,0 +1,150 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func testState(f func(*LightState)) LightState {
+	var s LightState
+	f(&s)
+	return s
+}
+func inGamut(g gamut, x, y float32) bool {
+	// Points clamped onto the edge of the gamut may be outside of it by a
+	// rounding error.
+	a, b := g.clamp(x, y)
+	return math.Abs(float64(a-x)) < 1e-5 && math.Abs(float64(b-y)) < 1e-5
+}
+func TestInterpolate(t *testing.T) {
+	v := []struct {
+		name string
+		a, b LightState
+		m    Blend
+		n    int
+		bri  []uint8
+		xy   bool
+		on   bool
+	}{
+		{name: "none", n: 0},
+		{
+			name: "brightness",
+			a:    testState(func(s *LightState) { s.SetBrightness(0) }),
+			b:    testState(func(s *LightState) { s.SetBrightness(100) }),
+			m:    BlendOKLab,
+			n:    4,
+			bri:  []uint8{25, 50, 75, 100},
+		},
+		{
+			name: "brightness-one",
+			a:    testState(func(s *LightState) { s.SetBrightness(80) }),
+			b:    testState(func(s *LightState) { s.On, s.mask = true, s.mask|maskOn }),
+			n:    2,
+			bri:  []uint8{80, 80},
+			on:   true,
+		},
+		{
+			name: "xy",
+			a:    testState(func(s *LightState) { s.SetXY(0.7, 0.3); s.SetBrightness(10) }),
+			b:    testState(func(s *LightState) { s.SetXY(0.15, 0.05); s.SetBrightness(200); s.SetTransition(time.Second * 2) }),
+			m:    BlendXY,
+			n:    4,
+			bri:  []uint8{58, 105, 153, 200},
+			xy:   true,
+		},
+		{
+			name: "lab",
+			a:    testState(func(s *LightState) { s.SetXY(0.7, 0.3) }),
+			b:    testState(func(s *LightState) { s.SetXY(0.15, 0.05); s.On, s.mask = true, s.mask|maskOn }),
+			m:    BlendLab,
+			n:    3,
+			xy:   true,
+			on:   true,
+		},
+		{
+			name: "oklab-temperature",
+			a:    testState(func(s *LightState) { s.SetTemperature(500) }),
+			b:    testState(func(s *LightState) { s.SetXY(0.2, 0.6) }),
+			m:    BlendOKLab,
+			n:    5,
+			xy:   true,
+		},
+		{
+			name: "color-one",
+			b:    testState(func(s *LightState) { s.SetXY(0.4, 0.4); s.SetTransition(time.Second) }),
+			m:    BlendLab,
+			n:    2,
+			xy:   true,
+		},
+	}
+	for _, c := range v {
+		r := Interpolate(c.a, c.b, c.n, c.m)
+		if len(r) != c.n {
+			t.Errorf("%s: Interpolate returned %d states, want %d", c.name, len(r), c.n)
+			continue
+		}
+		for i := range r {
+			if w := c.b.Transition / uint16(c.n); r[i].Transition != w {
+				t.Errorf("%s: step %d has transition %d, want %d", c.name, i, r[i].Transition, w)
+			}
+			if c.bri != nil && (r[i].mask&maskBrightness == 0 || r[i].Brightness != c.bri[i]) {
+				t.Errorf("%s: step %d has brightness %d, want %d", c.name, i, r[i].Brightness, c.bri[i])
+			}
+			if c.on && (r[i].mask&maskOn == 0 || !r[i].On) {
+				t.Errorf("%s: step %d does not turn the Light on", c.name, i)
+			}
+			if !c.xy {
+				if r[i].mask&maskXY != 0 {
+					t.Errorf("%s: step %d has an unexpected color", c.name, i)
+				}
+				continue
+			}
+			if r[i].mask&maskXY == 0 || !inGamut(*defaultGamut, r[i].XY[0], r[i].XY[1]) {
+				t.Errorf("%s: step %d has the color %v, want a color inside the gamut", c.name, i, r[i].XY)
+			}
+		}
+		if !c.xy || c.n == 0 {
+			continue
+		}
+		x, y := defaultGamut.clamp(c.b.XY[0], c.b.XY[1])
+		if l := r[len(r)-1]; l.XY[0] != x || l.XY[1] != y {
+			t.Errorf("%s: last step has the color %v, want %f, %f", c.name, l.XY, x, y)
+		}
+		if c.a.mask&maskXY == 0 && c.a.mask&maskTemperature == 0 && r[0].XY != r[len(r)-1].XY {
+			t.Errorf("%s: first step has the color %v, want %v", c.name, r[0].XY, r[len(r)-1].XY)
+		}
+	}
+}
+func TestInterpolateTemperature(t *testing.T) {
+	var a, b LightState
+	a.SetXY(0.2, 0.6)
+	b.SetTemperature(153)
+	r := Interpolate(a, b, 3, BlendOKLab)
+	if len(r) != 3 {
+		t.Fatalf("Interpolate returned %d states, want 3", len(r))
+	}
+	if l := r[2]; l.mask&maskXY != 0 || l.mask&maskTemperature == 0 || l.Temperature != 153 {
+		t.Fatalf("last step is %+v, want the temperature of 153", l)
+	}
+	for i := range r[:2] {
+		if r[i].mask&maskXY == 0 {
+			t.Errorf("step %d does not have a color", i)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Key is an access key (whitelist entry) that is allowed to access the Bridge.
+// The 'Name' value is the "devicetype" used when the key was created.
+type Key struct {
+	Created  time.Time
+	LastUsed time.Time
+	Key      string
+	Name     string
+}
+
+// Pair will create a new access key on the Bridge at the specified address and
+// return a Bridge that uses the new key, along with the clientkey (in hex) that
+// can be used for Entertainment streaming.
+//
+// The link button on the Bridge must be pressed before calling this function.
+// The devicetype is the name of the application and is limited to 40 characters,
+// such as "huectl#laptop".
+func Pair(x context.Context, address, devicetype string) (*Bridge, string, error) {
+	b, err := ConnectContext(x, address, "")
+	if err != nil {
+		return nil, "", err
+	}
+	k, c, err := b.pair(x, devicetype)
+	if err != nil {
+		return nil, "", err
+	}
+	b.setKey(k)
+	return b, c, nil
+}
+func (b *Bridge) setKey(k string) {
+	b.auth.Lock()
+	b.key, b.addr = k, b.base+"/api/"+k
+	b.auth.Unlock()
+}
+func (b *Bridge) pair(x context.Context, n string) (string, string, error) {
+	if len(n) == 0 || len(n) > 40 {
+		return "", "", &errval{s: `invalid devicetype "` + n + `"`}
+	}
+	d, err := json.Marshal(map[string]interface{}{"devicetype": n, "generateclientkey": true})
+	if err != nil {
+		return "", "", err
+	}
+	r, err := b.do(x, http.MethodPost, b.base+"/api", d)
+	if err != nil {
+		return "", "", err
+	}
+	var v struct {
+		Key    string `json:"username"`
+		Client string `json:"clientkey"`
+	}
+	if err = json.Unmarshal(r, &v); err != nil {
+		return "", "", &errval{s: `could not unmarshal pairing JSON`, e: err}
+	}
+	if len(v.Key) == 0 {
+		return "", "", &errval{s: `pairing response did not contain a key`}
+	}
+	return v.Key, v.Client, nil
+}
+
+// Key returns the access key used by this Bridge.
+func (b *Bridge) Key() string {
+	b.auth.RLock()
+	k := b.key
+	b.auth.RUnlock()
+	return k
+}
+
+// DeleteKey will remove the specified access key from the Bridge whitelist. Any
+// application using the key will no longer be able to access the Bridge.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge.
+func (b *Bridge) DeleteKey(x context.Context, k string) error {
+	if len(k) == 0 {
+		return &errval{s: `key cannot be empty`}
+	}
+	if _, err := b.request(x, http.MethodDelete, "/config/whitelist/"+k, nil); err != nil {
+		return err
+	}
+	b.lock.Lock()
+	if b.config != nil {
+		// A new slice is used, so any slices returned by 'Keys' are not changed.
+		r := make([]Key, 0, len(b.config.keys))
+		for i := range b.config.keys {
+			if b.config.keys[i].Key != k {
+				r = append(r, b.config.keys[i])
+			}
+		}
+		b.config.keys = r
+	}
+	b.lock.Unlock()
+	return nil
+}
+
+// Rotate will create a new access key with the specified devicetype, switch this
+// Bridge to the new key and then remove the old key. The new clientkey (in hex)
+// is returned.
+//
+// The link button on the Bridge must be pressed before calling this function.
+// Clip clients and Streams created before calling this function will still use
+// the old key and will stop working.
+//
+// If the old key could not be removed, the Bridge will still use the new key and
+// the error is returned.
+func (b *Bridge) Rotate(x context.Context, devicetype string) (string, error) {
+	k, c, err := b.pair(x, devicetype)
+	if err != nil {
+		return "", err
+	}
+	o := b.Key()
+	b.setKey(k)
+	if err = b.DeleteKey(x, o); err != nil {
+		return c, &errval{s: `could not remove old key`, e: err}
+	}
+	return c, nil
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+	b, s := newTestBridge(t, map[string]string{
+		"POST /api":   `[{"success":{"username":"newkey","clientkey":"0011"}}]`,
+		"GET /config": `{"name":"Bridge","apiversion":"1.50.0","whitelist":{"testkey":{"name":"old"},"other":{"name":"other"}}}`,
+	})
+	x := context.Background()
+	c, err := b.ConfigContext(x)
+	if err != nil {
+		t.Fatalf("ConfigContext failed: %s", err)
+	}
+	var (
+		w sync.WaitGroup
+		q = make(chan struct{})
+	)
+	w.Add(1)
+	go func() {
+		// Requests and key reads during Rotate must not race with the key change.
+		defer w.Done()
+		for {
+			select {
+			case <-q:
+				return
+			default:
+			}
+			b.request(x, "GET", "/config", nil)
+			c.Keys()
+		}
+	}()
+	k, err := b.Rotate(x, "test#rotate")
+	close(q)
+	w.Wait()
+	if err != nil {
+		t.Fatalf("Rotate failed: %s", err)
+	}
+	if k != "0011" || b.Key() != "newkey" {
+		t.Fatalf("Rotate returned clientkey %q and key %q, want \"0011\" and \"newkey\"", k, b.Key())
+	}
+	if v := c.Keys(); len(v) != 1 || v[0].Key != "other" {
+		t.Fatalf("Keys returned %+v, want only the \"other\" key", v)
+	}
+	checkRequests(t, s, `POST /api {"devicetype":"test#rotate","generateclientkey":true}`, "DELETE /config/whitelist/testkey")
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Device kind constants.
+const (
+	KindUnknown DeviceKind = iota
+	// KindOnOff is a Control that can only be switched on and off, such as a
+	// plug or an on/off light.
+	KindOnOff
+	// KindDimmable is a Light that supports brightness.
+	KindDimmable
+	// KindTemperature is a Light that supports brightness and color temperature.
+	KindTemperature
+	// KindColor is a Light that supports brightness and color, but not color
+	// temperature.
+	KindColor
+	// KindExtendedColor is a Light that supports brightness, color and color
+	// temperature.
+	KindExtendedColor
+)
+
+// DeviceKind is an integer representation of the features that a Light or
+// Control supports. Lights are any Controls with a kind of KindDimmable or
+// higher.
+type DeviceKind uint8
+
+// Kind returns the DeviceKind of this Control (or Light).
+func (c *Control) Kind() DeviceKind {
+	return c.kind
+}
+
+// String returns the name of the DeviceKind.
+func (k DeviceKind) String() string {
+	switch k {
+	case KindOnOff:
+		return "OnOff"
+	case KindDimmable:
+		return "Dimmable"
+	case KindTemperature:
+		return "ColorTemperature"
+	case KindColor:
+		return "Color"
+	case KindExtendedColor:
+		return "ExtendedColor"
+	}
+	return "Unknown"
+}
+
+// Color returns true if the DeviceKind supports color.
+func (k DeviceKind) Color() bool {
+	return k == KindColor || k == KindExtendedColor
+}
+
+// Temperature returns true if the DeviceKind supports color temperature.
+func (k DeviceKind) Temperature() bool {
+	return k == KindTemperature || k == KindExtendedColor
+}
+func kindOf(t string, c map[string]json.RawMessage, s json.RawMessage) DeviceKind {
+	switch v := strings.ToLower(t); {
+	case strings.HasPrefix(v, "extended color"):
+		return KindExtendedColor
+	case strings.HasPrefix(v, "color temperature"):
+		return KindTemperature
+	case strings.HasPrefix(v, "color"):
+		return KindColor
+	case strings.HasPrefix(v, "dimmable"):
+		return KindDimmable
+	case strings.HasPrefix(v, "on/off"), strings.HasPrefix(v, "onoff"):
+		return KindOnOff
+	}
+	// Unknown types are classified by the capabilities, then by the state values,
+	// as some third-party devices do not report capabilities.
+	var m map[string]json.RawMessage
+	json.Unmarshal(s, &m)
+	var (
+		_, ct = c["ct"]
+		_, cg = c["colorgamut"]
+	)
+	if !ct {
+		_, ct = m["ct"]
+	}
+	if !cg {
+		_, cg = m["xy"]
+	}
+	switch {
+	case ct && cg:
+		return KindExtendedColor
+	case cg:
+		return KindColor
+	case ct:
+		return KindTemperature
+	}
+	if _, ok := c["maxlumen"]; ok {
+		return KindDimmable
+	}
+	if _, ok := m["bri"]; ok {
+		return KindDimmable
+	}
+	if _, ok := m["on"]; ok {
+		return KindOnOff
+	}
+	return KindUnknown
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	v := []struct {
+		t, c, s string
+		k       DeviceKind
+	}{
+		{t: "Extended color light", k: KindExtendedColor},
+		{t: "Color temperature light", k: KindTemperature},
+		{t: "Color light", k: KindColor},
+		{t: "Dimmable light", k: KindDimmable},
+		{t: "On/Off plug-in unit", k: KindOnOff},
+		{t: "OnOff light", k: KindOnOff},
+		{t: "EXTENDED COLOR LIGHT", k: KindExtendedColor},
+		{t: "Other", c: `{"ct":{"min":153,"max":500},"colorgamut":[]}`, k: KindExtendedColor},
+		{t: "Other", c: `{"colorgamut":[]}`, k: KindColor},
+		{t: "Other", c: `{"ct":{"min":153,"max":500}}`, k: KindTemperature},
+		{t: "Other", c: `{"ct":{}}`, s: `{"xy":[0.3,0.3]}`, k: KindExtendedColor},
+		{t: "Other", s: `{"on":true,"bri":1,"xy":[0.3,0.3]}`, k: KindColor},
+		{t: "Other", s: `{"on":true,"bri":1,"ct":153}`, k: KindTemperature},
+		{t: "Other", c: `{"maxlumen":800}`, s: `{"on":true}`, k: KindDimmable},
+		{t: "Other", s: `{"on":true,"bri":1}`, k: KindDimmable},
+		{t: "Other", s: `{"on":true}`, k: KindOnOff},
+		{t: "Other", s: `{"reachable":true}`, k: KindUnknown},
+		{t: "", k: KindUnknown},
+	}
+	for _, x := range v {
+		var c map[string]json.RawMessage
+		if len(x.c) > 0 {
+			if err := json.Unmarshal([]byte(x.c), &c); err != nil {
+				t.Fatalf("Unmarshal(%s): unexpected error: %s", x.c, err)
+			}
+		}
+		if k := kindOf(x.t, c, json.RawMessage(x.s)); k != x.k {
+			t.Errorf("kindOf(%q, %s, %s) = %s, want %s", x.t, x.c, x.s, k, x.k)
+		}
+	}
+}
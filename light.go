@@ -15,7 +15,10 @@
 
 package hue
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // ErrNoColor is an error returned when attempting to set the color on a Light
 // when the Light does not support colors, meaning it is only has white support.
@@ -24,6 +27,7 @@ var ErrNoColor = &errval{s: `light does not support color`}
 // Light represents a controllable Hue Light. This can be used to control and set
 // the Light State.
 type Light struct {
+	ct    *ctRange
 	gamut *gamut
 	Control
 }
@@ -33,9 +37,56 @@ func (l *Light) Hue() uint16 {
 	return l.state.Hue
 }
 
+// Apply will send the values set in the LightState to the Light. Only the values
+// that have been set on the LightState will be sent.
+//
+// Any XY color values are clamped to the color gamut of the Light and color
+// temperature values are clamped to the color temperature range of the Light,
+// or converted to XY colors if the Light supports color but not color
+// temperature. Color values that the Light does not support are not
+// sent, so the same LightState can be applied to white and color Lights.
+//
+// This function does not use the 'Manual' attribute and will not send any
+// changes that are waiting for an 'Update*' function call.
+func (l *Light) Apply(x context.Context, s LightState) error {
+	m, g := l.supported(s.mask), l.colorGamut()
+	if m&maskTemperature != 0 && l.ct != nil {
+		s.Temperature = l.ct.clamp(s.Temperature)
+	}
+	if m&maskTemperature != 0 && m&maskXY == 0 && l.ct == nil && g != nil {
+		s.XY[0], s.XY[1] = xyFromTemperature(*g, s.Temperature)
+		m = (m | maskXY) &^ maskTemperature
+	}
+	if m&maskXY != 0 {
+		s.XY[0], s.XY[1] = g.clamp(s.XY[0], s.XY[1])
+	}
+	return l.apply(x, s.controlState, m)
+}
+
 // IsColor returns true if the Light supports colors.
 func (l *Light) IsColor() bool {
-	return bool(l.state.Color)
+	return l.state.Color != colorNone
+}
+func (l *Light) supported(m uint16) uint16 {
+	switch {
+	case l.state.Color == colorNone:
+		return m &^ (maskXY | maskHue | maskSaturation | maskTemperature | maskEffect)
+	case l.colorGamut() == nil:
+		return m &^ (maskXY | maskHue | maskSaturation | maskEffect)
+	}
+	return m
+}
+func (l *Light) colorGamut() *gamut {
+	// Color Lights that do not report a gamut use the default gamut, Lights
+	// without XY color support (including color temperature only Lights) return
+	// nil.
+	if l.gamut != nil {
+		return l.gamut
+	}
+	if l.kind.Color() {
+		return defaultGamut
+	}
+	return nil
 }
 
 // Effect returns a representation of the color effect that can be set.
@@ -58,6 +109,16 @@ func (l *Light) Temperature() uint16 {
 	return l.state.Temperature
 }
 
+// Kelvin returns the color temperature level of the Light in Kelvin.
+//
+// This function returns zero if the Light does not have a color temperature set.
+func (l *Light) Kelvin() uint16 {
+	if l.state.Temperature == 0 {
+		return 0
+	}
+	return uint16(1000000 / uint32(l.state.Temperature))
+}
+
 // XY returns the set color of the Light on the CIE 1931 XY axis.
 func (l *Light) XY() (float32, float32) {
 	return l.state.XY[0], l.state.XY[1]
@@ -71,7 +132,7 @@ func (l *Light) XY() (float32, float32) {
 // change the state once the 'Update*'function is called. Returns ErrNoColor if
 // the Light does not support color.
 func (l *Light) SetHue(h uint16) error {
-	if !l.state.Color {
+	if l.state.Color == colorNone {
 		return ErrNoColor
 	}
 	l.state.Hue = h
@@ -88,10 +149,11 @@ func (l *Light) SetHue(h uint16) error {
 // This function immediately returns if the 'Manual' attribute is "true" and will
 // change the state once the 'Update*'function is called.
 //
-// Hex strings MUST be formalized with at least 6 characters and may begin with a
-// '#' symbol. Returns ErrNoColor if the Light does not support color.
+// Hex strings MUST be formalized with 3, 4, 6 or 8 characters and may begin with
+// a '#' symbol. Any alpha value is ignored. Returns ErrNoColor if the Light does
+// not support color.
 func (l *Light) SetHex(h string) error {
-	if !l.state.Color {
+	if l.state.Color == colorNone {
 		return ErrNoColor
 	}
 	if l.gamut == nil {
@@ -104,6 +166,29 @@ func (l *Light) SetHex(h string) error {
 	return l.SetXY(x, y)
 }
 
+// SetColor will set the color of the Light to the specified CSS color string.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*'function is called.
+//
+// See 'ParseColor' for the supported formats. Returns ErrNoColor if the Light
+// does not support color.
+func (l *Light) SetColor(c string) error {
+	if l.state.Color == colorNone {
+		return ErrNoColor
+	}
+	if l.gamut == nil {
+		l.gamut = defaultGamut
+	}
+	x, y, err := xyFromString(*l.gamut, c)
+	if err != nil {
+		return err
+	}
+	return l.SetXY(x, y)
+}
+
 // SetEffect will set the light Effect of the Light to the specified value.
 //
 // This function returns any errors during setting the state.
@@ -148,7 +233,7 @@ func (l *Light) SetBrightness(b uint8) error {
 //
 // Returns ErrNoColor if the Light does not support color.
 func (l *Light) SetSaturation(s uint8) error {
-	if !l.state.Color {
+	if l.state.Color == colorNone {
 		return ErrNoColor
 	}
 	l.state.Saturation = s
@@ -171,19 +256,46 @@ func (l *Light) SetTransition(t time.Duration) {
 	l.state.Transition = uint16(t / (time.Millisecond * 100))
 }
 
+// SetKelvin will set the light color temperature of the Light to the specified
+// Kelvin value. The value is converted to mireds and clamped to the supported
+// color temperature range of the Light.
+//
+// Lights that support color but not color temperature will be set to the
+// equivalent XY color instead. See 'SetTemperature' for more info.
+//
+// This function returns any errors during setting the state.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*'function is called.
+//
+// Returns ErrNoColor if the Light does not support color.
+func (l *Light) SetKelvin(k uint16) error {
+	if l.ct == nil {
+		return l.SetTemperature(defaultRange.clamp(mireds(k)))
+	}
+	return l.SetTemperature(l.ct.clamp(mireds(k)))
+}
+
 // SetTemperature will set the light color temperature of the Light to the
-// specified value.
+// specified value in mireds.
 //
 // This function returns any errors during setting the state.
 //
 // This function immediately returns if the 'Manual' attribute is "true" and will
 // change the state once the 'Update*'function is called.
 //
+// Lights that support color but not color temperature (such as older Gamut A
+// lights) will instead be set to the equivalent XY point on the Planckian locus
+// that falls within the Light's color gamut.
+//
 // Returns ErrNoColor if the Light does not support color.
 func (l *Light) SetTemperature(t uint16) error {
-	if !l.state.Color {
+	if l.state.Color == colorNone {
 		return ErrNoColor
 	}
+	if l.ct == nil && l.gamut != nil {
+		return l.SetXY(xyFromTemperature(*l.gamut, t))
+	}
 	l.state.Temperature = t
 	if l.mask |= maskTemperature; l.Manual {
 		return nil
@@ -200,7 +312,7 @@ func (l *Light) SetTemperature(t uint16) error {
 //
 // Returns ErrNoColor if the Light does not support color.
 func (l *Light) SetXY(x float32, y float32) error {
-	if !l.state.Color {
+	if l.state.Color == colorNone {
 		return ErrNoColor
 	}
 	l.state.XY[0], l.state.XY[1] = x, y
@@ -218,9 +330,14 @@ func (l *Light) SetXY(x float32, y float32) error {
 // This function immediately returns if the 'Manual' attribute is "true" and will
 // change the state once the 'Update*' function is called.
 //
+// Any XY color values are clamped to the color gamut of the Light.
+//
 // NOTE: Not every device will support this function, mainly only first party
 // (Phillips) devices will have support for this.
 func (l *Light) SetCustomPowerOn(s LightState) error {
+	if c := l.colorGamut(); c != nil && s.mask&maskXY != 0 {
+		s.XY[0], s.XY[1] = c.clamp(s.XY[0], s.XY[1])
+	}
 	l.startup.Mode = startupCustom
 	l.startup.Settings = &s.controlState
 	if l.mask |= maskStartup; l.Manual {
@@ -238,7 +355,7 @@ func (l *Light) SetCustomPowerOn(s LightState) error {
 //
 // Returns ErrNoColor if the Light does not support color.
 func (l *Light) SetRGB(r uint8, g uint8, b uint8) error {
-	if !l.state.Color {
+	if l.state.Color == colorNone {
 		return ErrNoColor
 	}
 	if l.gamut == nil {
@@ -247,3 +364,66 @@ func (l *Light) SetRGB(r uint8, g uint8, b uint8) error {
 	x, y := xyFromRGB(*l.gamut, r, g, b)
 	return l.SetXY(x, y)
 }
+
+// Archetype returns the archetype of the Light, which describes the physical
+// shape of the Light, such as "sultanbulb" or "pendantround".
+func (l *Light) Archetype() string {
+	return l.config.Archetype
+}
+
+// Function returns the LightFunction of the Light, which describes if the Light
+// is used for task lighting, decoration or both.
+func (l *Light) Function() LightFunction {
+	return l.config.Function
+}
+
+// Direction returns the LightDirection of the Light, which describes the
+// direction the Light shines in.
+//
+// There is no setter for the direction, as it is a read-only value that is set
+// by the Light's model. The v1 API rejects changes to it and the CLIP API v2
+// light metadata only allows changing the name, archetype and function.
+func (l *Light) Direction() LightDirection {
+	return l.config.Direction
+}
+
+// SetArchetype will change the archetype of the Light, such as "sultanbulb" or
+// "pendantround".
+//
+// This function returns any errors during setting the archetype.
+//
+// The archetype is read-only in the v1 API, so it is changed through the Light's
+// CLIP API v2 resource.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (l *Light) SetArchetype(s string) error {
+	if len(s) == 0 {
+		return &errval{s: `archetype cannot be empty`}
+	}
+	l.config.Archetype = s
+	if l.mask |= maskArchetype; l.Manual {
+		return nil
+	}
+	return l.UpdateContext(l.bridge.ctx)
+}
+
+// SetFunction will change the LightFunction of the Light.
+//
+// This function returns any errors during setting the function.
+//
+// The function is read-only in the v1 API, so it is changed through the Light's
+// CLIP API v2 resource.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (l *Light) SetFunction(f LightFunction) error {
+	if f == FunctionUnknown || f > FunctionMixed {
+		return &errval{s: `invalid LightFunction value`}
+	}
+	l.config.Function = f
+	if l.mask |= maskFunction; l.Manual {
+		return nil
+	}
+	return l.UpdateContext(l.bridge.ctx)
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+// Light function constants.
+const (
+	FunctionUnknown LightFunction = iota
+	// FunctionFunctional is a Light that is used for task lighting.
+	FunctionFunctional
+	// FunctionDecorative is a Light that is used for decoration and ambiance.
+	FunctionDecorative
+	// FunctionMixed is a Light that is used for both task lighting and
+	// decoration.
+	FunctionMixed
+)
+
+// Light direction constants.
+const (
+	DirectionUnknown LightDirection = iota
+	DirectionOmni
+	DirectionUp
+	DirectionDown
+	DirectionHorizontal
+	DirectionVertical
+)
+
+// LightFunction is an integer representation of how a Light is used, which is
+// used by the Hue app to pick colors and brightness when creating scenes.
+type LightFunction uint8
+
+// LightDirection is an integer representation of the direction that a Light
+// shines in.
+type LightDirection uint8
+
+// String returns the name of the LightFunction.
+func (f LightFunction) String() string {
+	switch f {
+	case FunctionFunctional:
+		return "functional"
+	case FunctionDecorative:
+		return "decorative"
+	case FunctionMixed:
+		return "mixed"
+	}
+	return "unknown"
+}
+
+// String returns the name of the LightDirection.
+func (d LightDirection) String() string {
+	switch d {
+	case DirectionOmni:
+		return "omnidirectional"
+	case DirectionUp:
+		return "upwards"
+	case DirectionDown:
+		return "downwards"
+	case DirectionHorizontal:
+		return "horizontal"
+	case DirectionVertical:
+		return "vertical"
+	}
+	return "unknown"
+}
+
+// MarshalJSON fulfils the JSON Marshaler interface.
+func (f LightFunction) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + f.String() + `"`), nil
+}
+
+// MarshalJSON fulfils the JSON Marshaler interface.
+func (d LightDirection) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON fulfils the JSON Unmarshaler interface. Unknown values are
+// read as 'FunctionUnknown'.
+func (f *LightFunction) UnmarshalJSON(d []byte) error {
+	if len(d) < 2 || d[0] != '"' {
+		return &errval{s: `invalid LightFunction value`}
+	}
+	switch string(d[1 : len(d)-1]) {
+	case "functional":
+		*f = FunctionFunctional
+	case "decorative":
+		*f = FunctionDecorative
+	case "mixed":
+		*f = FunctionMixed
+	default:
+		*f = FunctionUnknown
+	}
+	return nil
+}
+
+// UnmarshalJSON fulfils the JSON Unmarshaler interface. Unknown values are
+// read as 'DirectionUnknown'.
+func (d *LightDirection) UnmarshalJSON(b []byte) error {
+	if len(b) < 2 || b[0] != '"' {
+		return &errval{s: `invalid LightDirection value`}
+	}
+	switch string(b[1 : len(b)-1]) {
+	case "omnidirectional":
+		*d = DirectionOmni
+	case "upwards":
+		*d = DirectionUp
+	case "downwards":
+		*d = DirectionDown
+	case "horizontal":
+		*d = DirectionHorizontal
+	case "vertical":
+		*d = DirectionVertical
+	default:
+		*d = DirectionUnknown
+	}
+	return nil
+}
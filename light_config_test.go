@@ -0,0 +1,111 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLightFunctionUnmarshal(t *testing.T) {
+	for _, v := range [...]struct {
+		s string
+		f LightFunction
+	}{
+		{`"functional"`, FunctionFunctional},
+		{`"decorative"`, FunctionDecorative},
+		{`"mixed"`, FunctionMixed},
+		{`"unknown"`, FunctionUnknown},
+		{`"other"`, FunctionUnknown},
+		{`""`, FunctionUnknown},
+	} {
+		var f LightFunction
+		if err := json.Unmarshal([]byte(v.s), &f); err != nil {
+			t.Errorf("Unmarshal(%s): unexpected error: %s", v.s, err)
+			continue
+		}
+		if f != v.f {
+			t.Errorf("Unmarshal(%s) = %s, want %s", v.s, f, v.f)
+		}
+	}
+	var f LightFunction
+	if err := json.Unmarshal([]byte(`1`), &f); err == nil {
+		t.Errorf("Unmarshal(1) did not return an error")
+	}
+}
+func TestLightDirectionUnmarshal(t *testing.T) {
+	for _, v := range [...]struct {
+		s string
+		d LightDirection
+	}{
+		{`"omnidirectional"`, DirectionOmni},
+		{`"upwards"`, DirectionUp},
+		{`"downwards"`, DirectionDown},
+		{`"horizontal"`, DirectionHorizontal},
+		{`"vertical"`, DirectionVertical},
+		{`"sideways"`, DirectionUnknown},
+		{`""`, DirectionUnknown},
+	} {
+		var d LightDirection
+		if err := json.Unmarshal([]byte(v.s), &d); err != nil {
+			t.Errorf("Unmarshal(%s): unexpected error: %s", v.s, err)
+			continue
+		}
+		if d != v.d {
+			t.Errorf("Unmarshal(%s) = %s, want %s", v.s, d, v.d)
+		}
+	}
+	var d LightDirection
+	if err := json.Unmarshal([]byte(`null`), &d); err == nil {
+		t.Errorf("Unmarshal(null) did not return an error")
+	}
+}
+func TestLightMetadata(t *testing.T) {
+	b, s := newTestBridge(t, map[string]string{
+		"GET /lights":                    testLights("1", "2"),
+		"GET /sensors":                   testSensors,
+		"GET /clip/v2/resource/light":    `{"errors":[],"data":[{"id":"l1","id_v1":"/lights/1","type":"light","metadata":{"name":"1"}},{"id":"l2","id_v1":"/lights/2","type":"light","metadata":{"name":"2"}}]}`,
+		"PUT /clip/v2/resource/light/l2": `{"errors":[],"data":[{"rid":"l2","rtype":"light"}]}`,
+	})
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	v := l["2"]
+	v.Manual = true
+	v.SetPowerOn(StartupResume)
+	v.SetFunction(FunctionDecorative)
+	if err = v.Update(); err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	checkRequests(t, s,
+		`PUT /clip/v2/resource/light/l2 {"metadata":{"function":"decorative"}}`,
+		`PUT /lights/2 {"config":{"startup":{"mode":"powerfail"}}}`,
+	)
+	if v.Function() != FunctionDecorative {
+		t.Errorf("Function() = %s, want %s", v.Function(), FunctionDecorative)
+	}
+	v.Manual = false
+	if err = v.SetArchetype("pendantround"); err != nil {
+		t.Fatalf("SetArchetype failed: %s", err)
+	}
+	if r := s.requests(); len(r) != 3 || r[2] != `PUT /clip/v2/resource/light/l2 {"metadata":{"archetype":"pendantround"}}` {
+		t.Fatalf("server received requests %q, want only a CLIP API v2 archetype change", r)
+	}
+	if err = l["1"].SetArchetype(""); err == nil {
+		t.Fatalf("SetArchetype with an empty value did not return an error")
+	}
+}
@@ -18,12 +18,53 @@ package hue
 import "time"
 
 // LightState is a representation of settings that can be used to change the
-// state of a LightState.
+// state of a Light, Control or Group.
+//
+// Only the values that are set on a LightState are sent when it is applied, so
+// a LightState can be used as a reusable preset that can be applied to any
+// target with the 'Apply' functions.
 type LightState struct {
 	mask uint16
 	controlState
 }
 
+// SetOn will set the LightState to switch into the specified state.
+func (s *LightState) SetOn(e bool) {
+	s.On = e
+	s.mask |= maskOn
+}
+
+// Fields returns the names of the values that are set on the LightState. The
+// names match the JSON names used by the Bridge, such as "on", "bri" or "xy".
+func (s LightState) Fields() []string {
+	r := make([]string, 0, 8)
+	if s.mask&maskOn != 0 {
+		r = append(r, "on")
+	}
+	if s.mask&maskXY != 0 {
+		r = append(r, "xy")
+	}
+	if s.mask&maskHue != 0 {
+		r = append(r, "hue")
+	}
+	if s.mask&maskAlert != 0 {
+		r = append(r, "alert")
+	}
+	if s.mask&maskEffect != 0 {
+		r = append(r, "effect")
+	}
+	if s.mask&maskBrightness != 0 {
+		r = append(r, "bri")
+	}
+	if s.mask&maskSaturation != 0 {
+		r = append(r, "sat")
+	}
+	if s.mask&maskTemperature != 0 {
+		r = append(r, "ct")
+	}
+	return r
+}
+
 // SetAlert will change the LightState into the specified Alert state.
 func (s *LightState) SetAlert(a Alert) {
 	s.Alert = a
@@ -53,14 +94,40 @@ func (s *LightState) SetBrightness(b uint8) {
 // specified value.
 func (s *LightState) SetSaturation(v uint8) {
 	s.Saturation = v
+	s.mask |= maskSaturation
 }
 
 // SetHex will set the color of the LightState to the specified hex string value.
 //
-// Hex strings MUST be formalized with at least 6 characters and may begin with
-// a '#' symbol.
+// The color is not clamped to a color gamut until the LightState is applied.
+//
+// Hex strings MUST be formalized with 3, 4, 6 or 8 characters and may begin with
+// a '#' symbol. Any alpha value is ignored.
 func (s *LightState) SetHex(h string) error {
-	x, y, err := xyFromHex(*defaultGamut, h)
+	x, y, err := xyFromHex(*fullGamut, h)
+	if err != nil {
+		return err
+	}
+	s.SetXY(x, y)
+	return nil
+}
+
+// SetKelvin will set the light color temperature of the LightState to the
+// specified Kelvin value. The value is converted to mireds and clamped to the
+// default color temperature range (2000K - 6500K). The value is also clamped to
+// the color temperature range of each Light it is applied to.
+func (s *LightState) SetKelvin(k uint16) {
+	s.SetTemperature(defaultRange.clamp(mireds(k)))
+}
+
+// SetColor will set the color of the LightState to the specified CSS color
+// string.
+//
+// The color is not clamped to a color gamut until the LightState is applied.
+//
+// See 'ParseColor' for the supported formats.
+func (s *LightState) SetColor(c string) error {
+	x, y, err := xyFromString(*fullGamut, c)
 	if err != nil {
 		return err
 	}
@@ -69,7 +136,7 @@ func (s *LightState) SetHex(h string) error {
 }
 
 // SetTemperature will set the light color temperature of the LightState to the
-// specified value.
+// specified value in mireds.
 func (s *LightState) SetTemperature(t uint16) {
 	s.Temperature = t
 	s.mask |= maskTemperature
@@ -95,7 +162,9 @@ func (s *LightState) SetTransition(t time.Duration) {
 }
 
 // SetRGB will set the light color of the LightState to the specified RGB value.
+//
+// The color is not clamped to a color gamut until the LightState is applied.
 func (s *LightState) SetRGB(r uint8, g uint8, b uint8) {
-	x, y := xyFromRGB(*defaultGamut, r, g, b)
+	x, y := xyFromRGB(*fullGamut, r, g, b)
 	s.SetXY(x, y)
 }
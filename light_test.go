@@ -0,0 +1,96 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testApplyLights = `{
+"1":{"name":"Color","uniqueid":"00:17:88:01:00:00:00:01-0b","type":"Color light","modelid":"X1","productname":"Color lamp","state":{"on":true,"bri":1,"hue":0,"sat":0,"xy":[0.3,0.3],"alert":"none","effect":"none","colormode":"xy","reachable":true}},
+"2":{"name":"White","uniqueid":"00:17:88:01:00:00:00:02-0b","type":"Color temperature light","modelid":"X2","productname":"White lamp","state":{"on":true,"bri":1,"ct":300,"alert":"none","colormode":"ct","reachable":true},"capabilities":{"control":{"ct":{"min":153,"max":454}}}}
+}`
+
+func testApplyBody(t *testing.T, r string) map[string]json.RawMessage {
+	i := strings.IndexByte(r, '{')
+	if i < 0 {
+		t.Fatalf("request %q does not have a body", r)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(r[i:]), &m); err != nil {
+		t.Fatalf("request %q has an invalid body: %s", r, err)
+	}
+	return m
+}
+func TestApplyDefaultGamut(t *testing.T) {
+	b, s := newTestBridge(t, map[string]string{"GET /lights": testApplyLights, "GET /sensors": testSensors})
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	var v LightState
+	v.SetXY(0.9, 0.05)
+	v.SetBrightness(10)
+	for _, i := range []string{"1", "2"} {
+		if err = l[i].Apply(context.Background(), v); err != nil {
+			t.Fatalf("Apply(%s) failed: %s", i, err)
+		}
+	}
+	var k LightState
+	k.SetTemperature(370)
+	if err = l["1"].Apply(context.Background(), k); err != nil {
+		t.Fatalf("Apply(1) failed: %s", err)
+	}
+	r := s.requests()
+	if len(r) != 3 {
+		t.Fatalf("server received requests %q, want 3 requests", r)
+	}
+	for _, q := range []string{r[0], r[2]} {
+		if !strings.HasPrefix(q, "PUT /lights/1/state ") {
+			t.Fatalf("request %q was not sent to the color Light", q)
+		}
+		var p [2]float32
+		if err = json.Unmarshal(testApplyBody(t, q)["xy"], &p); err != nil {
+			t.Fatalf("request %q does not have an XY color: %s", q, err)
+		}
+		if !inGamut(*defaultGamut, p[0], p[1]) {
+			t.Errorf("request %q has the color %v, want a color inside the default gamut", q, p)
+		}
+	}
+	if r[1] != `PUT /lights/2/state {"bri":10}` {
+		t.Errorf("request %q was sent to the color temperature Light, want only the brightness", r[1])
+	}
+}
+func TestApplyTemperatureRange(t *testing.T) {
+	b, s := newTestBridge(t, map[string]string{"GET /lights": testApplyLights, "GET /sensors": testSensors})
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	var v, k LightState
+	v.SetKelvin(2000)
+	k.SetTemperature(100)
+	if err = l["2"].Apply(context.Background(), v); err != nil {
+		t.Fatalf("Apply failed: %s", err)
+	}
+	if err = l["2"].Apply(context.Background(), k); err != nil {
+		t.Fatalf("Apply failed: %s", err)
+	}
+	checkRequests(t, s, `PUT /lights/2/state {"ct":454}`, `PUT /lights/2/state {"ct":153}`)
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// searchInterval is the rate that the Bridge is checked for the results of a
+// search. A search takes about 40 seconds to complete.
+const searchInterval = time.Second * 2
+
+// searchStart is the number of checks that can pass without the search being
+// started by the Bridge before an error is returned.
+const searchStart = 5
+
+// SearchLights will make the Bridge search for new Lights and Controls and will
+// block until the search has completed. Up to 10 serial numbers may be supplied
+// to search for Lights that are not found by a normal search, such as Lights
+// that were reset or are already paired with another Bridge.
+//
+// The new Lights and Controls are added to the Bridge and returned. This function
+// returns an error wrapping 'ErrLimit' if the Bridge cannot store any more Lights.
+func (b *Bridge) SearchLights(x context.Context, serials ...string) ([]*Light, []*Control, error) {
+	if err := b.limit(x, "Light", 1, func(c *Capabilities) Capacity { return c.Lights }); err != nil {
+		return nil, nil, err
+	}
+	n, err := b.search(x, "/lights", serials)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		l []*Light
+		c []*Control
+	)
+	for _, i := range n {
+		r, err := b.request(x, http.MethodGet, "/lights/"+i, nil)
+		if err != nil {
+			return l, c, err
+		}
+		var d decoder
+		if err = d.unmarshal(i, b, r); err != nil {
+			return l, c, &errval{s: `could not unmarshal Light "` + i + `" JSON`, e: err}
+		}
+		b.lock.Lock()
+		if d.l != nil {
+			if l = append(l, d.l); b.lights != nil {
+				b.lights[i] = d.l
+			}
+		} else if c = append(c, d.c); b.controls != nil {
+			b.controls[i] = d.c
+		}
+		b.lock.Unlock()
+	}
+	return l, c, nil
+}
+
+// SearchSensors will make the Bridge search for new Sensors and will block until
+// the search has completed.
+//
+// The new Sensors are added to the Bridge and returned. This function returns an
+// error wrapping 'ErrLimit' if the Bridge cannot store any more Sensors.
+func (b *Bridge) SearchSensors(x context.Context) ([]*Sensor, error) {
+	if err := b.limit(x, "Sensor", 1, func(c *Capabilities) Capacity { return c.Sensors.Capacity }); err != nil {
+		return nil, err
+	}
+	n, err := b.search(x, "/sensors", nil)
+	if err != nil {
+		return nil, err
+	}
+	var o []*Sensor
+	for _, i := range n {
+		r, err := b.request(x, http.MethodGet, "/sensors/"+i, nil)
+		if err != nil {
+			return o, err
+		}
+		s := new(Sensor)
+		if err = s.unmarshal(i, b, r); err != nil {
+			return o, &errval{s: `could not unmarshal Sensor "` + i + `" JSON`, e: err}
+		}
+		b.lock.Lock()
+		if o = append(o, s); b.sensors != nil {
+			b.sensors[i] = s
+		}
+		b.lock.Unlock()
+	}
+	return o, nil
+}
+
+// Touchlink will make the Bridge perform a touchlink, which adds the closest
+// Light to the Bridge, even if it is paired with another Bridge. The Light must
+// be close to the Bridge and will blink once it has been added.
+//
+// Use 'SearchLights' to add the Light once the touchlink has completed, which
+// takes about 8 seconds.
+func (b *Bridge) Touchlink(x context.Context) error {
+	_, err := b.request(x, http.MethodPut, "/config", []byte(`{"touchlink":true}`))
+	return err
+}
+func (b *Bridge) search(x context.Context, p string, s []string) ([]string, error) {
+	if len(s) > 10 {
+		return nil, &errval{s: `cannot search for more than 10 serial numbers`}
+	}
+	var d []byte
+	if len(s) > 0 {
+		var err error
+		if d, err = json.Marshal(map[string][]string{"deviceid": s}); err != nil {
+			return nil, err
+		}
+	}
+	// The "lastscan" value is "active" while searching, "none" if no search has
+	// started yet and the time of the last search once it has completed. The
+	// previous value is read first, so the results of an older search are not
+	// returned.
+	l, _, err := b.scan(x, p)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = b.request(x, http.MethodPost, p, d); err != nil {
+		return nil, err
+	}
+	t := time.NewTicker(searchInterval)
+	defer t.Stop()
+	for i := 0; ; {
+		select {
+		case <-x.Done():
+			return nil, x.Err()
+		case <-t.C:
+		}
+		v, m, err := b.scan(x, p)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case v == "active":
+			i = 0
+			continue
+		case v == l || v == "none" || len(v) == 0:
+			if i++; i >= searchStart {
+				return nil, &errval{s: `search did not start on the Bridge`}
+			}
+			continue
+		}
+		o := make([]string, 0, len(m))
+		for k := range m {
+			o = append(o, k)
+		}
+		sort.Strings(o)
+		return o, nil
+	}
+}
+func (b *Bridge) scan(x context.Context, p string) (string, map[string]json.RawMessage, error) {
+	r, err := b.request(x, http.MethodGet, p+"/new", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	var m map[string]json.RawMessage
+	if err = json.Unmarshal(r, &m); err != nil {
+		return "", nil, &errval{s: `could not unmarshal search JSON`, e: err}
+	}
+	var v string
+	json.Unmarshal(m["lastscan"], &v)
+	delete(m, "lastscan")
+	return v, m, nil
+}
@@ -44,8 +44,9 @@ type Sensor struct {
 	ID, Product, UUID string
 	name              string
 
-	Make, Model string
-	mask        uint16
+	Make, Model, Version string
+	Software             SoftwareUpdate
+	mask                 uint16
 
 	Manual bool
 }
@@ -391,6 +392,16 @@ func (s *Sensor) unmarshal(i string, b *Bridge, d []byte) error {
 			return err
 		}
 	}
+	if v, ok = m["swversion"]; ok {
+		if err := json.Unmarshal(v, &s.Version); err != nil {
+			return err
+		}
+	}
+	if v, ok = m["swupdate"]; ok {
+		if err := json.Unmarshal(v, &s.Software); err != nil {
+			return err
+		}
+	}
 	var a map[string]json.RawMessage
 	if v, ok = m["state"]; !ok {
 		return &errval{s: `missing "state" parameter value`}
@@ -0,0 +1,219 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Snapshot is a captured copy of the state of Lights and Controls that can be
+// used to restore them to the state they were in when the Snapshot was taken.
+//
+// Snapshots can be created using the 'Snapshot' function on a Light, Control,
+// Group or the Bridge.
+type Snapshot struct {
+	e []snapshotEntry
+}
+type snapshotEntry struct {
+	c *Control
+	s controlState
+	m uint16
+}
+
+// Len returns the number of Lights and Controls captured in the Snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.e)
+}
+func (s *Snapshot) add(c *Control, l bool) {
+	if !c.state.Reachable {
+		// Unreachable devices cannot be restored, their reported state is also
+		// most likely not accurate.
+		return
+	}
+	e := snapshotEntry{c: c, s: c.state, m: maskOn}
+	if e.s.Transition = 0; !l || !e.s.On {
+		// Color and brightness values cannot be changed when a Light is off, so
+		// only the power state is restored.
+		s.e = append(s.e, e)
+		return
+	}
+	e.m |= maskBrightness | maskEffect
+	switch e.s.Color {
+	case colorXY:
+		e.m |= maskXY
+	case colorHS:
+		e.m |= maskHue | maskSaturation
+	case colorCT:
+		e.m |= maskTemperature
+	}
+	s.e = append(s.e, e)
+}
+func (c *Control) snapshot(x context.Context, l bool) (*Snapshot, error) {
+	// The state is fetched before taking the Bridge lock, so lookups are not
+	// blocked while waiting on the Bridge.
+	v, err := c.fetch(x)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Snapshot)
+	c.bridge.lock.Lock()
+	c.state, c.known = v, v
+	s.add(c, l)
+	c.bridge.lock.Unlock()
+	return s, nil
+}
+func (c *Control) fetch(x context.Context) (controlState, error) {
+	r, err := c.bridge.request(x, http.MethodGet, "/lights/"+c.ID, nil)
+	if err != nil {
+		return controlState{}, err
+	}
+	var v struct {
+		State controlState `json:"state"`
+	}
+	if err = json.Unmarshal(r, &v); err != nil {
+		return controlState{}, &errval{s: `could not parse response JSON`, e: err}
+	}
+	return v.State, nil
+}
+func (b *Bridge) refresh(x context.Context) error {
+	if b.lights == nil || b.controls == nil {
+		return b.getControls(x)
+	}
+	r, err := b.request(x, http.MethodGet, "/lights", nil)
+	if err != nil || len(r) == 0 {
+		return err
+	}
+	var m map[string]struct {
+		State controlState `json:"state"`
+	}
+	if err = json.Unmarshal(r, &m); err != nil {
+		return &errval{s: "could not unmarshal Light JSON", e: err}
+	}
+	for k, v := range m {
+		if l, ok := b.lights[k]; ok {
+			l.state, l.known = v.State, v.State
+			continue
+		}
+		if c, ok := b.controls[k]; ok {
+			c.state, c.known = v.State, v.State
+		}
+	}
+	return nil
+}
+
+// Snapshot will capture the current state of the Light, which can be restored
+// later using the 'Restore' function on the returned Snapshot.
+//
+// This function will fetch the current state of the Light from the Bridge before
+// capturing it and returns any errors that occur during fetching.
+func (l *Light) Snapshot(x context.Context) (*Snapshot, error) {
+	return l.snapshot(x, true)
+}
+
+// Snapshot will capture the current state of the Control, which can be restored
+// later using the 'Restore' function on the returned Snapshot.
+//
+// This function will fetch the current state of the Control from the Bridge
+// before capturing it and returns any errors that occur during fetching.
+func (c *Control) Snapshot(x context.Context) (*Snapshot, error) {
+	return c.snapshot(x, false)
+}
+
+// Snapshot will capture the current state of every Light and Control in the
+// Group, which can be restored later using the 'Restore' function on the
+// returned Snapshot.
+//
+// This function will fetch the current state of all Lights and Controls from
+// the Bridge before capturing it and returns any errors that occur during
+// fetching.
+func (g *Group) Snapshot(x context.Context) (*Snapshot, error) {
+	g.bridge.lock.Lock()
+	err := g.bridge.refresh(x)
+	g.bridge.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{e: make([]snapshotEntry, 0, len(g.Lights)+len(g.Controls))}
+	for _, l := range g.Lights {
+		s.add(&l.Control, true)
+	}
+	for _, c := range g.Controls {
+		s.add(c, false)
+	}
+	return s, nil
+}
+
+// Snapshot will capture the current state of every Light and Control connected
+// to the Bridge, which can be restored later using the 'Restore' function on
+// the returned Snapshot.
+//
+// This function will fetch the current state of all Lights and Controls from
+// the Bridge before capturing it and returns any errors that occur during
+// fetching.
+func (b *Bridge) Snapshot(x context.Context) (*Snapshot, error) {
+	b.lock.Lock()
+	if err := b.refresh(x); err != nil {
+		b.lock.Unlock()
+		return nil, err
+	}
+	s := &Snapshot{e: make([]snapshotEntry, 0, len(b.lights)+len(b.controls))}
+	for _, l := range b.lights {
+		s.add(&l.Control, true)
+	}
+	for _, c := range b.controls {
+		s.add(c, false)
+	}
+	b.lock.Unlock()
+	return s, nil
+}
+
+// Restore will set every Light and Control in the Snapshot back to the state
+// that was captured, using the specified transition time. Each Light will be
+// set back using the color mode (XY, hue/saturation or temperature) that it
+// was in when captured.
+//
+// Commands are sent at a rate that respects the Bridge rate limits. This
+// function will attempt to restore every Light and Control and returns the
+// first error that occurred, if any.
+func (s *Snapshot) Restore(x context.Context, t time.Duration) error {
+	if len(s.e) == 0 {
+		return nil
+	}
+	var (
+		k   = time.NewTicker(rateLight)
+		err error
+	)
+	for i := range s.e {
+		if i > 0 {
+			select {
+			case <-x.Done():
+				k.Stop()
+				return x.Err()
+			case <-k.C:
+			}
+		}
+		v := s.e[i].s
+		v.Transition = uint16(t / (time.Millisecond * 100))
+		if e := s.e[i].c.apply(x, v, s.e[i].m); e != nil && err == nil {
+			err = e
+		}
+	}
+	k.Stop()
+	return err
+}
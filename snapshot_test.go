@@ -0,0 +1,62 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSnapshotConcurrent(t *testing.T) {
+	b, _ := newTestBridge(t, map[string]string{
+		"GET /lights":   testLights("1", "2"),
+		"GET /lights/1": strings.NewReplacer("%s", "1").Replace(testLight),
+		"GET /sensors":  testSensors,
+	})
+	l, err := b.Lights()
+	if err != nil {
+		t.Fatalf("Lights failed: %s", err)
+	}
+	var (
+		w sync.WaitGroup
+		e = make(chan error, 8)
+	)
+	for i := 0; i < 4; i++ {
+		w.Add(2)
+		go func() {
+			defer w.Done()
+			s, err := l["1"].Snapshot(context.Background())
+			if err == nil && s.Len() != 1 {
+				t.Errorf("Light Snapshot has %d entries, want 1", s.Len())
+			}
+			e <- err
+		}()
+		go func() {
+			defer w.Done()
+			_, err := b.Snapshot(context.Background())
+			e <- err
+		}()
+	}
+	w.Wait()
+	close(e)
+	for err := range e {
+		if err != nil {
+			t.Fatalf("Snapshot failed: %s", err)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StreamPort is the UDP port used by the Bridge for Entertainment streaming.
+const StreamPort = 2100
+
+// StreamRate is the default rate that a Stream will send frames to the Bridge,
+// which is 50 frames a second.
+const StreamRate = time.Second / 50
+
+const (
+	// StreamRGB is a StreamSpace that sends colors as 16-bit RGB values.
+	StreamRGB StreamSpace = iota
+	// StreamXY is a StreamSpace that sends colors as 16-bit CIE XY values and a
+	// 16-bit brightness value.
+	StreamXY
+)
+
+// StreamSpace is the color space used by a Stream when sending colors.
+type StreamSpace uint8
+
+// Stream is an Entertainment streaming session, which allows for quickly setting
+// the color of Lights in an Entertainment group or configuration without the
+// rate limits of the REST API.
+//
+// Channels are identified by the Light ID when using an Entertainment Group
+// (HueStream v1) and by the channel ID when using a CLIP API v2 Entertainment
+// Configuration (HueStream v2).
+//
+// Streams are safe to use concurrently.
+type Stream struct {
+	conn *dtlsConn
+	stop func() error
+	e    map[uint16][3]uint16
+
+	id   string
+	lock sync.Mutex
+	Rate time.Duration
+	seq  uint8
+
+	Space StreamSpace
+}
+
+// NewStream will complete a DTLS handshake over the supplied connection and
+// return a Stream that can be used to send colors. The access key is used as the
+// PSK identity and the clientkey (in hex) is used as the PSK.
+//
+// If the supplied ID is a CLIP API v2 Entertainment Configuration ID (UUID), the
+// Stream will use HueStream v2, otherwise HueStream v1 is used.
+//
+// This function does not start streaming on the Bridge. This allows for using
+// any connection, such as a local stand-in server. Use the 'Stream' functions on
+// a Group or Clip to start streaming on a Bridge.
+func NewStream(x context.Context, c net.Conn, key, clientkey, id string) (*Stream, error) {
+	p, err := hex.DecodeString(clientkey)
+	if err != nil {
+		return nil, &errval{s: "invalid clientkey value", e: err}
+	}
+	if len(id) > 0 && len(id) != 36 {
+		return nil, &errval{s: `invalid Entertainment Configuration ID "` + id + `"`}
+	}
+	d, err := dtlsClient(x, c, key, p)
+	if err != nil {
+		return nil, &errval{s: "could not complete DTLS handshake", e: err}
+	}
+	return &Stream{conn: d, id: id, e: make(map[uint16][3]uint16), Rate: StreamRate}, nil
+}
+
+// Stream will start streaming on this Entertainment Group and will return a
+// Stream that uses HueStream v1. The clientkey is the hex value returned when
+// the access key was created with 'generateclientkey'.
+//
+// Closing the Stream will stop streaming on the Group.
+func (g *Group) Stream(x context.Context, clientkey string) (*Stream, error) {
+	if g.Type != Entertainment {
+		return nil, &errval{s: `group "` + g.ID + `" is not an Entertainment group`}
+	}
+	if err := g.bridge.require(x, "1.22.0", "Entertainment streaming"); err != nil {
+		return nil, err
+	}
+	if _, err := g.bridge.request(x, http.MethodPut, "/groups/"+g.ID, []byte(`{"stream":{"active":true}}`)); err != nil {
+		return nil, err
+	}
+	f := func() error {
+		_, err := g.bridge.request(g.bridge.ctx, http.MethodPut, "/groups/"+g.ID, []byte(`{"stream":{"active":false}}`))
+		return err
+	}
+	s, err := dialStream(x, g.bridge.base, g.bridge.Key(), clientkey, "")
+	if err != nil {
+		f()
+		return nil, err
+	}
+	s.stop = f
+	return s, nil
+}
+
+// Stream will start streaming on the CLIP API v2 Entertainment Configuration with
+// the specified ID and will return a Stream that uses HueStream v2. The
+// clientkey is the hex value returned when the access key was created with
+// 'generateclientkey'.
+//
+// Closing the Stream will stop streaming on the Entertainment Configuration.
+func (c *Clip) Stream(x context.Context, id, clientkey string) (*Stream, error) {
+	if err := c.Put(x, ClipTypeEntertainmentConfiguration, id, map[string]string{"action": "start"}); err != nil {
+		return nil, err
+	}
+	f := func() error {
+		return c.Put(c.ctx, ClipTypeEntertainmentConfiguration, id, map[string]string{"action": "stop"})
+	}
+	s, err := dialStream(x, c.addr, c.key, clientkey, id)
+	if err != nil {
+		f()
+		return nil, err
+	}
+	s.stop = f
+	return s, nil
+}
+func dialStream(x context.Context, base, key, clientkey, id string) (*Stream, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	c, err := d.DialContext(x, "udp", net.JoinHostPort(u.Hostname(), strconv.Itoa(StreamPort)))
+	if err != nil {
+		return nil, &errval{s: `could not connect to "` + u.Hostname() + `"`, e: err}
+	}
+	s, err := NewStream(x, c, key, clientkey, id)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close will stop the Stream and close the connection. If the Stream was
+// started by a Group or Clip, streaming will be stopped on the Bridge.
+func (s *Stream) Close() error {
+	s.lock.Lock()
+	err := s.conn.Close()
+	if s.stop != nil {
+		if e := s.stop(); err == nil {
+			err = e
+		}
+		s.stop = nil
+	}
+	s.lock.Unlock()
+	return err
+}
+
+// Clear will remove all the channel colors from the Stream. Channels that are
+// not set will not be sent.
+func (s *Stream) Clear() {
+	s.lock.Lock()
+	s.e = make(map[uint16][3]uint16, len(s.e))
+	s.lock.Unlock()
+}
+
+// Set will set the raw 16-bit color values for the specified channel. The values
+// are sent as-is and are interpreted by the Bridge based on the StreamSpace.
+func (s *Stream) Set(channel, a, b, c uint16) {
+	s.lock.Lock()
+	s.e[channel] = [3]uint16{a, b, c}
+	s.lock.Unlock()
+}
+
+// SetRGB will set the color of the specified channel to the RGB values.
+func (s *Stream) SetRGB(channel uint16, r, g, b uint8) {
+	if s.Space == StreamRGB {
+		s.Set(channel, uint16(r)*0x101, uint16(g)*0x101, uint16(b)*0x101)
+		return
+	}
+	x, y := xyFromRGB(*fullGamut, r, g, b)
+	l := r
+	if g > l {
+		l = g
+	}
+	if b > l {
+		l = b
+	}
+	s.Set(channel, uint16(x*0xFFFF), uint16(y*0xFFFF), uint16(l)*0x101)
+}
+
+// SetXY will set the color of the specified channel to the XY color values with
+// the supplied brightness.
+func (s *Stream) SetXY(channel uint16, x, y float32, brightness uint8) {
+	if s.Space == StreamXY {
+		s.Set(channel, uint16(x*0xFFFF), uint16(y*0xFFFF), uint16(brightness)*0x101)
+		return
+	}
+	r, g, b := rgbFromXy(*fullGamut, float32(brightness)/0xFF, x, y)
+	s.Set(channel, uint16(r)*0x101, uint16(g)*0x101, uint16(b)*0x101)
+}
+
+// SetColor will set the color of the specified channel to the color string.
+// The string may be any value accepted by 'ParseColor'.
+func (s *Stream) SetColor(channel uint16, c string) error {
+	r, g, b, err := ParseColor(c)
+	if err != nil {
+		return err
+	}
+	s.SetRGB(channel, r, g, b)
+	return nil
+}
+
+// Send will send the current channel colors to the Bridge as a single frame.
+//
+// HueStream v1 frames are limited to 10 Lights and HueStream v2 frames are
+// limited to 20 channels, so more than one frame may be sent.
+func (s *Stream) Send() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var (
+		n = 10
+		k = make([]uint16, 0, len(s.e))
+	)
+	if len(s.id) > 0 {
+		n = 20
+	}
+	for i := range s.e {
+		k = append(k, i)
+	}
+	sort.Slice(k, func(i, j int) bool { return k[i] < k[j] })
+	for i := 0; i < len(k) || i == 0; i += n {
+		e := i + n
+		if e > len(k) {
+			e = len(k)
+		}
+		if _, err := s.conn.Write(s.frame(k[i:e])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (s *Stream) frame(k []uint16) []byte {
+	b := make([]byte, 16, 16+36+len(k)*9)
+	copy(b, "HueStream")
+	if b[9], b[11], b[14] = 1, s.seq, byte(s.Space); len(s.id) > 0 {
+		b[9] = 2
+		b = append(b, s.id...)
+	}
+	for _, i := range k {
+		v := s.e[i]
+		if len(s.id) > 0 {
+			b = append(b, byte(i))
+		} else {
+			b = append(b, 0, byte(i>>8), byte(i))
+		}
+		b = append(b, byte(v[0]>>8), byte(v[0]), byte(v[1]>>8), byte(v[1]), byte(v[2]>>8), byte(v[2]))
+	}
+	s.seq++
+	return b
+}
+
+// Run will send the current channel colors to the Bridge at the Stream 'Rate'
+// until the supplied Context is canceled or an error occurs.
+//
+// The Bridge will stop streaming if no frames are received for ten seconds, so
+// this function should be used to keep the Stream active.
+//
+// This function does not close the Stream and returns nil when the Context is
+// canceled.
+func (s *Stream) Run(x context.Context) error {
+	r := s.Rate
+	if r <= 0 {
+		r = StreamRate
+	}
+	t := time.NewTicker(r)
+	defer t.Stop()
+	for {
+		if err := s.Send(); err != nil {
+			return err
+		}
+		select {
+		case <-x.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Software update state constants.
+const (
+	UpdateUnknown UpdateState = iota
+	// UpdateNone means that the Bridge or device is up to date.
+	UpdateNone
+	// UpdateNotUpdatable means that the device cannot be updated by the Bridge.
+	UpdateNotUpdatable
+	// UpdateTransferring means that an update is being downloaded or sent to the
+	// device.
+	UpdateTransferring
+	// UpdateReady means that an update is ready to be installed. For the Bridge,
+	// this means that all devices are ready to install.
+	UpdateReady
+	// UpdateAnyReady means that an update is ready to be installed on at least one
+	// device. This is only reported by the Bridge.
+	UpdateAnyReady
+	// UpdateInstalling means that an update is being installed.
+	UpdateInstalling
+)
+
+// UpdateState is an integer representation of the software update state of the
+// Bridge or a device.
+type UpdateState uint8
+
+// SoftwareUpdate is the software update status of a device, such as a Light or
+// Sensor.
+type SoftwareUpdate struct {
+	LastInstall sensorTime  `json:"lastinstall"`
+	State       UpdateState `json:"state"`
+}
+
+// BridgeUpdate is the software update status of the Bridge and all connected
+// devices. The 'State' value is the combined state of all devices and the
+// 'Bridge' value is the state of the Bridge itself.
+//
+// The 'AutoTime' value is the time of day that automatic updates are installed,
+// as an offset from midnight.
+type BridgeUpdate struct {
+	LastChange  time.Time
+	LastInstall time.Time
+	AutoTime    time.Duration
+
+	State, Bridge UpdateState
+
+	AutoInstall, Checking bool
+}
+
+// String returns the name of the UpdateState.
+func (u UpdateState) String() string {
+	switch u {
+	case UpdateNone:
+		return "noupdates"
+	case UpdateNotUpdatable:
+		return "notupdatable"
+	case UpdateTransferring:
+		return "transferring"
+	case UpdateReady:
+		return "readytoinstall"
+	case UpdateAnyReady:
+		return "anyreadytoinstall"
+	case UpdateInstalling:
+		return "installing"
+	}
+	return "unknown"
+}
+
+// Active returns true if the UpdateState means an update is being transferred or
+// installed.
+func (u UpdateState) Active() bool {
+	return u == UpdateTransferring || u == UpdateInstalling
+}
+
+// UnmarshalJSON will read the UpdateState from a JSON string.
+func (u *UpdateState) UnmarshalJSON(d []byte) error {
+	var s string
+	if err := json.Unmarshal(d, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "noupdates":
+		*u = UpdateNone
+	case "notupdatable":
+		*u = UpdateNotUpdatable
+	case "transferring":
+		*u = UpdateTransferring
+	case "readytoinstall", "allreadytoinstall":
+		*u = UpdateReady
+	case "anyreadytoinstall":
+		*u = UpdateAnyReady
+	case "installing":
+		*u = UpdateInstalling
+	default:
+		*u = UpdateUnknown
+	}
+	return nil
+}
+
+// UnmarshalJSON will read the BridgeUpdate from the "swupdate2" JSON object.
+func (u *BridgeUpdate) UnmarshalJSON(d []byte) error {
+	var v struct {
+		Bridge struct {
+			LastInstall sensorTime  `json:"lastinstall"`
+			State       UpdateState `json:"state"`
+		} `json:"bridge"`
+		Auto struct {
+			Time string `json:"updatetime"`
+			On   bool   `json:"on"`
+		} `json:"autoinstall"`
+		LastChange sensorTime  `json:"lastchange"`
+		State      UpdateState `json:"state"`
+		Check      bool        `json:"checkforupdate"`
+	}
+	if err := json.Unmarshal(d, &v); err != nil {
+		return err
+	}
+	u.LastChange, u.LastInstall = v.LastChange.Time, v.Bridge.LastInstall.Time
+	u.State, u.Bridge, u.AutoInstall, u.Checking = v.State, v.Bridge.State, v.Auto.On, v.Check
+	if u.AutoTime = 0; len(v.Auto.Time) == 9 && v.Auto.Time[0] == 'T' {
+		if t, err := time.Parse("15:04:05", v.Auto.Time[1:]); err == nil {
+			u.AutoTime = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+		}
+	}
+	return nil
+}
+
+// SetAutoInstall will change if updates are installed automatically by the Bridge
+// and the time of day, as an offset from midnight, to install them.
+//
+// This function returns any errors during setting the value.
+//
+// This function immediately returns if the 'Manual' attribute is "true" and will
+// change the state once the 'Update*' function is called.
+func (c *BridgeConfig) SetAutoInstall(e bool, t time.Duration) error {
+	if t < 0 || t >= time.Hour*24 {
+		return &errval{s: `invalid update time "` + t.String() + `"`}
+	}
+	c.Software.AutoInstall, c.Software.AutoTime = e, t.Truncate(time.Second)
+	if c.mask |= configAutoInstall; c.Manual {
+		return nil
+	}
+	return c.UpdateContext(c.bridge.ctx)
+}
+func (u BridgeUpdate) autoTime() string {
+	var (
+		s = int(u.AutoTime / time.Second)
+		b = []byte{'T', 0, 0, ':', 0, 0, ':', 0, 0}
+	)
+	for i, v := range [...]int{s / 3600, (s / 60) % 60, s % 60} {
+		b[1+i*3], b[2+i*3] = byte('0'+v/10), byte('0'+v%10)
+	}
+	return string(b)
+}
+
+func (u BridgeUpdate) ready() bool {
+	return u.State == UpdateReady || u.State == UpdateAnyReady || u.Bridge == UpdateReady
+}
+func (b *Bridge) updateConfig(r []byte) error {
+	// The cached BridgeConfig is changed in place, so any references to it are
+	// kept up to date.
+	b.lock.Lock()
+	b.install = false
+	c := b.config
+	b.lock.Unlock()
+	if c != nil {
+		return c.unmarshal(r)
+	}
+	c = &BridgeConfig{bridge: b}
+	if err := c.unmarshal(r); err != nil {
+		return err
+	}
+	b.lock.Lock()
+	if b.config == nil {
+		b.config = c
+	}
+	b.lock.Unlock()
+	return nil
+}
+
+// CheckForUpdate will make the Bridge check for software updates for the Bridge
+// and all connected devices. Use 'WaitForUpdate' to wait for the check to
+// complete.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge.
+func (b *Bridge) CheckForUpdate(x context.Context) error {
+	_, err := b.request(x, http.MethodPut, "/config", []byte(`{"swupdate2":{"checkforupdate":true}}`))
+	return err
+}
+
+// InstallUpdate will make the Bridge install all the software updates that are
+// ready to be installed. Use 'WaitForUpdate' to wait for the updates to complete.
+//
+// This will return an error if there's a problem connecting or accessing the
+// Bridge.
+func (b *Bridge) InstallUpdate(x context.Context) error {
+	if _, err := b.request(x, http.MethodPut, "/config", []byte(`{"swupdate2":{"install":true}}`)); err != nil {
+		return err
+	}
+	b.lock.Lock()
+	b.install = true
+	b.lock.Unlock()
+	return nil
+}
+
+// WaitForUpdate will block until the Bridge is no longer checking for, transferring
+// or installing updates, checking the Bridge status at the specified interval.
+// The final BridgeUpdate status is returned, which may report that updates are
+// ready to install.
+//
+// If 'InstallUpdate' was called, updates that are ready to install are not
+// treated as complete until the Bridge has started transferring or installing
+// them, as the Bridge may not start the install right away.
+//
+// The Bridge may restart while installing an update, so connection errors are
+// ignored until the Context is canceled. The cached BridgeConfig is refreshed
+// once this function returns without error.
+func (b *Bridge) WaitForUpdate(x context.Context, d time.Duration) (BridgeUpdate, error) {
+	if d <= 0 {
+		d = time.Second * 5
+	}
+	b.lock.RLock()
+	i := b.install
+	b.lock.RUnlock()
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for a := false; ; {
+		r, err := b.request(x, http.MethodGet, "/config", nil)
+		if err == nil {
+			var c BridgeConfig
+			if err = c.unmarshal(r); err != nil {
+				return BridgeUpdate{}, err
+			}
+			switch s := c.Software; {
+			case s.Checking || s.State.Active() || s.Bridge.Active():
+				a = true
+			case a || !i || !s.ready():
+				return s, b.updateConfig(r)
+			}
+		}
+		select {
+		case <-x.Done():
+			if err == nil {
+				err = x.Err()
+			}
+			return BridgeUpdate{}, &errval{s: `could not wait for update`, e: err}
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateStateUnmarshal(t *testing.T) {
+	v := []struct {
+		s string
+		u UpdateState
+		a bool
+	}{
+		{s: `"noupdates"`, u: UpdateNone},
+		{s: `"notupdatable"`, u: UpdateNotUpdatable},
+		{s: `"transferring"`, u: UpdateTransferring, a: true},
+		{s: `"readytoinstall"`, u: UpdateReady},
+		{s: `"allreadytoinstall"`, u: UpdateReady},
+		{s: `"anyreadytoinstall"`, u: UpdateAnyReady},
+		{s: `"installing"`, u: UpdateInstalling, a: true},
+		{s: `"unknown"`, u: UpdateUnknown},
+		{s: `"somethingnew"`, u: UpdateUnknown},
+	}
+	for _, c := range v {
+		var u UpdateState
+		if err := json.Unmarshal([]byte(c.s), &u); err != nil {
+			t.Errorf("Unmarshal(%s): unexpected error: %s", c.s, err)
+			continue
+		}
+		if u != c.u || u.Active() != c.a {
+			t.Errorf("Unmarshal(%s) = %s (active %t), want %s (active %t)", c.s, u, u.Active(), c.u, c.a)
+		}
+	}
+	var u UpdateState
+	if err := json.Unmarshal([]byte(`1`), &u); err == nil {
+		t.Errorf("Unmarshal(1): expected an error")
+	}
+}
+func TestWaitForUpdate(t *testing.T) {
+	const c = `{"name":"Bridge","apiversion":"1.50.0","swupdate2":{"state":"%s","bridge":{"state":"noupdates"},"checkforupdate":false}}`
+	var (
+		b, s = newTestBridge(t, map[string]string{})
+		x    = context.Background()
+		f    = func(v string) string { return strings.Replace(c, "%s", v, 1) }
+	)
+	s.queue("GET /config", f("readytoinstall"))
+	o, err := b.ConfigContext(x)
+	if err != nil {
+		t.Fatalf("ConfigContext failed: %s", err)
+	}
+	if err = b.InstallUpdate(x); err != nil {
+		t.Fatalf("InstallUpdate failed: %s", err)
+	}
+	s.queue("GET /config", f("readytoinstall"), f("readytoinstall"), f("installing"), f("noupdates"))
+	u, err := b.WaitForUpdate(x, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForUpdate failed: %s", err)
+	}
+	if u.State != UpdateNone {
+		t.Fatalf("WaitForUpdate returned state %s, want %s", u.State, UpdateNone)
+	}
+	if o.Software.State != UpdateNone {
+		t.Fatalf("cached BridgeConfig has state %s, want %s", o.Software.State, UpdateNone)
+	}
+	// Without an install request, updates that are ready are complete.
+	s.queue("GET /config", f("anyreadytoinstall"))
+	if u, err = b.WaitForUpdate(x, time.Millisecond); err != nil || u.State != UpdateAnyReady {
+		t.Fatalf("WaitForUpdate returned %s, %v, want %s", u.State, err, UpdateAnyReady)
+	}
+}
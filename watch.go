@@ -0,0 +1,395 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	// EventLight is an EventType that indicates the state of a Light or Control
+	// was changed. The 'Old' and 'New' values will be LightStates that only have
+	// the changed values set, which can be listed with the 'Fields' function.
+	EventLight EventType = iota
+	// EventReachable is an EventType that indicates a Light, Control or Sensor
+	// became reachable or unreachable. The 'Old' and 'New' values will be bools.
+	EventReachable
+	// EventSensor is an EventType that indicates a Sensor reported a new value.
+	// The 'Key' value will be the name of the value and the 'Old' and 'New'
+	// values will be the previous and current values.
+	EventSensor
+	// EventButton is an EventType that indicates a button was pressed on a
+	// Sensor. The 'New' value will be the button event code as a float64. This
+	// event is sent even if the same button event was repeated.
+	EventButton
+	// EventAdded is an EventType that indicates a Light, Control, Sensor or Group
+	// was added to the Bridge.
+	EventAdded
+	// EventRemoved is an EventType that indicates a Light, Control, Sensor or
+	// Group was removed from the Bridge.
+	EventRemoved
+	// EventGroup is an EventType that indicates a Group was changed. The 'Key'
+	// value will be the name of the changed value ("name", "lights", "any_on" or
+	// "all_on") and the 'Old' and 'New' values will be the previous and current
+	// values.
+	EventGroup
+	// EventError is an EventType that indicates an error occurred while polling
+	// the Bridge. The 'New' value will be the error. Polling will continue after
+	// this event.
+	EventError
+)
+
+// Event is a change that was detected by a Bridge 'Watch' function.
+type Event struct {
+	Time     time.Time
+	Old, New interface{}
+
+	// Resource is the type of the device or Group that changed and will be one
+	// of "lights", "sensors" or "groups".
+	Resource string
+	ID, Name string
+	Key      string
+
+	Type EventType
+}
+
+// EventType represents the type of change an Event describes.
+type EventType uint8
+
+type watcher struct {
+	b *Bridge
+	f func(Event)
+
+	lights  map[string]watchLight
+	sensors map[string]watchSensor
+	groups  map[string]watchGroup
+	raw     map[string]string
+}
+type watchLight struct {
+	Name  string       `json:"name"`
+	State controlState `json:"state"`
+}
+type watchGroup struct {
+	Name   string   `json:"name"`
+	Lights []string `json:"lights"`
+	State  struct {
+		AnyOn bool `json:"any_on"`
+		AllOn bool `json:"all_on"`
+	} `json:"state"`
+}
+type watchSensor struct {
+	Name   string                     `json:"name"`
+	State  map[string]json.RawMessage `json:"state"`
+	Config sensorConfig               `json:"config"`
+}
+
+// String returns the name of the EventType.
+func (e EventType) String() string {
+	switch e {
+	case EventLight:
+		return "light"
+	case EventReachable:
+		return "reachable"
+	case EventSensor:
+		return "sensor"
+	case EventButton:
+		return "button"
+	case EventAdded:
+		return "added"
+	case EventRemoved:
+		return "removed"
+	case EventGroup:
+		return "group"
+	}
+	return "error"
+}
+
+// Watch will poll the Bridge at the specified interval and will send an Event to
+// the returned channel for each change that is detected.
+//
+// The channel will be closed once the supplied Context is canceled. Events are
+// not dropped, so the channel must be read from to allow polling to continue.
+//
+// The first poll is done before this function returns and is used as the
+// starting state, any errors during the first poll are returned.
+func (b *Bridge) Watch(x context.Context, d time.Duration) (<-chan Event, error) {
+	var (
+		c = make(chan Event, 64)
+		w = &watcher{b: b}
+	)
+	w.f = func(e Event) {
+		select {
+		case c <- e:
+		case <-x.Done():
+		}
+	}
+	if err := w.poll(x); err != nil {
+		close(c)
+		return nil, err
+	}
+	go func() {
+		w.run(x, d)
+		close(c)
+	}()
+	return c, nil
+}
+
+// WatchFunc will poll the Bridge at the specified interval and will call the
+// supplied function for each change that is detected.
+//
+// This function will block until the supplied Context is canceled and returns
+// any errors that occur during the first poll, which is used as the starting
+// state. Errors during any later polls are sent as 'EventError' Events.
+func (b *Bridge) WatchFunc(x context.Context, d time.Duration, f func(Event)) error {
+	w := &watcher{b: b, f: f}
+	if err := w.poll(x); err != nil {
+		return err
+	}
+	w.run(x, d)
+	return nil
+}
+func (w *watcher) run(x context.Context, d time.Duration) {
+	t := time.NewTicker(d)
+	for {
+		select {
+		case <-x.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if err := w.poll(x); err != nil && x.Err() == nil {
+			w.f(Event{Type: EventError, Time: time.Now(), New: err})
+		}
+	}
+}
+func (w *watcher) poll(x context.Context) error {
+	l, err := w.fetch(x, "/lights")
+	if err != nil {
+		return err
+	}
+	s, err := w.fetch(x, "/sensors")
+	if err != nil {
+		return err
+	}
+	g, err := w.fetch(x, "/groups")
+	if err != nil {
+		return err
+	}
+	var (
+		r  = make(map[string]string, len(l)+len(s)+len(g))
+		nl = make(map[string]watchLight, len(l))
+		ns = make(map[string]watchSensor, len(s))
+		ng = make(map[string]watchGroup, len(g))
+	)
+	// Decode everything before sending any Events, so an error part way through
+	// leaves the previous state untouched and the next poll can retry.
+	for k, v := range l {
+		if r["lights/"+k] = string(v); w.raw["lights/"+k] == string(v) {
+			nl[k] = w.lights[k]
+			continue
+		}
+		var n watchLight
+		if err = json.Unmarshal(v, &n); err != nil {
+			return &errval{s: `could not unmarshal Light "` + k + `" JSON`, e: err}
+		}
+		nl[k] = n
+	}
+	for k, v := range s {
+		if r["sensors/"+k] = string(v); w.raw["sensors/"+k] == string(v) {
+			ns[k] = w.sensors[k]
+			continue
+		}
+		var n watchSensor
+		if err = json.Unmarshal(v, &n); err != nil {
+			return &errval{s: `could not unmarshal Sensor "` + k + `" JSON`, e: err}
+		}
+		ns[k] = n
+	}
+	for k, v := range g {
+		if r["groups/"+k] = string(v); w.raw["groups/"+k] == string(v) {
+			ng[k] = w.groups[k]
+			continue
+		}
+		var n watchGroup
+		if err = json.Unmarshal(v, &n); err != nil {
+			return &errval{s: `could not unmarshal Group "` + k + `" JSON`, e: err}
+		}
+		ng[k] = n
+	}
+	if w.raw != nil {
+		w.events(time.Now(), r, nl, ns, ng)
+	}
+	w.lights, w.sensors, w.groups, w.raw = nl, ns, ng, r
+	return nil
+}
+func (w *watcher) events(t time.Time, r map[string]string, l map[string]watchLight, s map[string]watchSensor, g map[string]watchGroup) {
+	for k, n := range l {
+		if o, ok := w.lights[k]; !ok {
+			w.f(Event{Type: EventAdded, Time: t, Resource: "lights", ID: k, Name: n.Name})
+		} else if w.raw["lights/"+k] != r["lights/"+k] {
+			w.light(t, k, o, n)
+		}
+	}
+	for k, n := range s {
+		if o, ok := w.sensors[k]; !ok {
+			w.f(Event{Type: EventAdded, Time: t, Resource: "sensors", ID: k, Name: n.Name})
+		} else if w.raw["sensors/"+k] != r["sensors/"+k] {
+			w.sensor(t, k, o, n)
+		}
+	}
+	for k, n := range g {
+		if o, ok := w.groups[k]; !ok {
+			w.f(Event{Type: EventAdded, Time: t, Resource: "groups", ID: k, Name: n.Name})
+		} else if w.raw["groups/"+k] != r["groups/"+k] {
+			w.group(t, k, o, n)
+		}
+	}
+	for k, v := range w.lights {
+		if _, ok := l[k]; !ok {
+			w.f(Event{Type: EventRemoved, Time: t, Resource: "lights", ID: k, Name: v.Name})
+		}
+	}
+	for k, v := range w.sensors {
+		if _, ok := s[k]; !ok {
+			w.f(Event{Type: EventRemoved, Time: t, Resource: "sensors", ID: k, Name: v.Name})
+		}
+	}
+	for k, v := range w.groups {
+		if _, ok := g[k]; !ok {
+			w.f(Event{Type: EventRemoved, Time: t, Resource: "groups", ID: k, Name: v.Name})
+		}
+	}
+}
+func (w *watcher) fetch(x context.Context, u string) (map[string]json.RawMessage, error) {
+	r, err := w.b.request(x, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]json.RawMessage)
+	if len(r) == 0 {
+		return m, nil
+	}
+	if err = json.Unmarshal(r, &m); err != nil {
+		return nil, &errval{s: `could not parse response JSON`, e: err}
+	}
+	return m, nil
+}
+func (w *watcher) light(t time.Time, i string, o, n watchLight) {
+	if o.State.Reachable != n.State.Reachable {
+		w.f(Event{
+			Type: EventReachable, Time: t, Resource: "lights", ID: i, Name: n.Name,
+			Old: o.State.Reachable, New: n.State.Reachable,
+		})
+	}
+	m := changed(o.State, n.State)
+	if m == 0 {
+		return
+	}
+	e := Event{Type: EventLight, Time: t, Resource: "lights", ID: i, Name: n.Name}
+	e.Old, e.New = LightState{mask: m, controlState: o.State}, LightState{mask: m, controlState: n.State}
+	w.f(e)
+}
+func changed(o, n controlState) uint16 {
+	var m uint16
+	if o.On != n.On {
+		m |= maskOn
+	}
+	if o.Alert != n.Alert {
+		m |= maskAlert
+	}
+	if o.Effect != n.Effect {
+		m |= maskEffect
+	}
+	if o.Brightness != n.Brightness {
+		m |= maskBrightness
+	}
+	// Only the values of the current color mode are compared, the values of the
+	// other modes are not updated by the Bridge.
+	switch x := o.Color != n.Color; n.Color {
+	case colorXY:
+		if x || o.XY != n.XY {
+			m |= maskXY
+		}
+	case colorCT:
+		if x || o.Temperature != n.Temperature {
+			m |= maskTemperature
+		}
+	case colorHS:
+		if x || o.Hue != n.Hue {
+			m |= maskHue
+		}
+		if x || o.Saturation != n.Saturation {
+			m |= maskSaturation
+		}
+	}
+	return m
+}
+func (w *watcher) group(t time.Time, i string, o, n watchGroup) {
+	e := Event{Type: EventGroup, Time: t, Resource: "groups", ID: i, Name: n.Name}
+	if o.Name != n.Name {
+		e.Key, e.Old, e.New = "name", o.Name, n.Name
+		w.f(e)
+	}
+	if !equal(o.Lights, n.Lights) {
+		e.Key, e.Old, e.New = "lights", o.Lights, n.Lights
+		w.f(e)
+	}
+	if o.State.AnyOn != n.State.AnyOn {
+		e.Key, e.Old, e.New = "any_on", o.State.AnyOn, n.State.AnyOn
+		w.f(e)
+	}
+	if o.State.AllOn != n.State.AllOn {
+		e.Key, e.Old, e.New = "all_on", o.State.AllOn, n.State.AllOn
+		w.f(e)
+	}
+}
+func (w *watcher) sensor(t time.Time, i string, o, n watchSensor) {
+	if o.Config.Reachable != n.Config.Reachable {
+		w.f(Event{
+			Type: EventReachable, Time: t, Resource: "sensors", ID: i, Name: n.Name,
+			Old: o.Config.Reachable, New: n.Config.Reachable,
+		})
+	}
+	u := string(o.State["lastupdated"]) != string(n.State["lastupdated"])
+	for k, v := range n.State {
+		if k == "lastupdated" {
+			continue
+		}
+		if k == "buttonevent" {
+			if !u {
+				continue
+			}
+			var b float64
+			if json.Unmarshal(v, &b) == nil {
+				w.f(Event{Type: EventButton, Time: t, Resource: "sensors", ID: i, Name: n.Name, Key: k, New: b})
+			}
+			continue
+		}
+		p, ok := o.State[k]
+		if ok && string(p) == string(v) {
+			continue
+		}
+		e := Event{Type: EventSensor, Time: t, Resource: "sensors", ID: i, Name: n.Name, Key: k}
+		if ok {
+			json.Unmarshal(p, &e.Old)
+		}
+		json.Unmarshal(v, &e.New)
+		w.f(e)
+	}
+}
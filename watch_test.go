@@ -0,0 +1,92 @@
+// Copyright (C) 2021 - 2023 iDigitalFlame
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package hue
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testWatchRoutes() map[string]string {
+	return map[string]string{
+		"GET /lights":  testLights("1", "2"),
+		"GET /sensors": testSensors,
+		"GET /groups":  `{"1":{"name":"Room","type":"Room","lights":["1","2"],"state":{"any_on":false,"all_on":false}}}`,
+	}
+}
+func TestWatch(t *testing.T) {
+	b, s := newTestBridge(t, testWatchRoutes())
+	x, f := context.WithTimeout(context.Background(), 5*time.Second)
+	defer f()
+	c, err := b.Watch(x, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Watch failed: %s", err)
+	}
+	s.set("GET /lights", strings.Replace(testLights("1", "2"), `"on":false,"bri":1`, `"on":true,"bri":1`, 1))
+	s.set("GET /groups", `{"1":{"name":"Room","type":"Room","lights":["1","2"],"state":{"any_on":true,"all_on":false}},"2":{"name":"Zone","type":"Zone","lights":["2"]}}`)
+	var e []Event
+	for len(e) < 3 {
+		select {
+		case v := <-c:
+			e = append(e, v)
+		case <-x.Done():
+			t.Fatalf("timeout waiting for Events, received %v", e)
+		}
+	}
+	var l, a, g bool
+	for _, v := range e {
+		switch {
+		case v.Type == EventLight && v.ID == "1":
+			f := v.New.(LightState).Fields()
+			l = len(f) == 1 && f[0] == "on" && v.New.(LightState).On
+		case v.Type == EventAdded && v.Resource == "groups" && v.ID == "2":
+			a = v.Name == "Zone"
+		case v.Type == EventGroup && v.ID == "1":
+			g = v.Key == "any_on" && v.New == true
+		}
+	}
+	if !l || !a || !g {
+		t.Fatalf("unexpected Events received: %+v", e)
+	}
+}
+func TestWatchPollError(t *testing.T) {
+	var (
+		e    []Event
+		b, s = newTestBridge(t, testWatchRoutes())
+		w    = &watcher{b: b, f: func(v Event) { e = append(e, v) }}
+		x    = context.Background()
+	)
+	if err := w.poll(x); err != nil {
+		t.Fatalf("poll failed: %s", err)
+	}
+	s.set("GET /lights", strings.Replace(testLights("1", "2"), `"on":false,"bri":1`, `"on":true,"bri":1`, 1))
+	s.set("GET /sensors", `{"1":{"name":1}}`)
+	if err := w.poll(x); err == nil {
+		t.Fatalf("poll with an invalid Sensor: expected an error")
+	}
+	if len(e) > 0 {
+		t.Fatalf("poll with an invalid Sensor sent Events: %+v", e)
+	}
+	s.set("GET /sensors", testSensors)
+	if err := w.poll(x); err != nil {
+		t.Fatalf("poll failed: %s", err)
+	}
+	if len(e) != 1 || e[0].Type != EventLight || e[0].ID != "1" {
+		t.Fatalf("poll after an error sent Events %+v, want a single Light Event", e)
+	}
+}